@@ -0,0 +1,380 @@
+// Package archive keeps a local on disk copy of station weather data that is
+// synced incrementally from IEM
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+const stateFile = "state.json"
+
+// StationState is the sync state of a station in the archive
+type StationState struct {
+	LastObservation time.Time `json:"last_observation"` // Time of the latest archived observation
+	LastSync        time.Time `json:"last_sync"`        // Time the station was last synced
+}
+
+type archiveState struct {
+	Stations map[string]*StationState `json:"stations"`
+}
+
+// Archive stores weather data as a CSV file per station in a directory, written
+// with WriteWeatherData so missing values (M) and traces (T) read back as they
+// were fetched. Observations are appended in time order so a station's file is
+// only ever appended to by a sync
+type Archive struct {
+	dir     string
+	weather iem.WeatherService
+
+	// Date stations with no archived data are synced from
+	Since time.Time
+
+	mu    sync.Mutex
+	state *archiveState
+}
+
+// Open opens or creates an archive in dir that syncs with a WeatherService
+// (client.Weather()). New stations are synced from since
+func Open(dir string, weather iem.WeatherService, since time.Time) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	a := &Archive{
+		dir:     dir,
+		weather: weather,
+		Since:   since,
+		state:   &archiveState{Stations: map[string]*StationState{}},
+	}
+
+	file, err := os.ReadFile(filepath.Join(dir, stateFile))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return a, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(file, a.state); err != nil {
+		return nil, err
+	}
+
+	if a.state.Stations == nil {
+		a.state.Stations = map[string]*StationState{}
+	}
+
+	return a, nil
+}
+
+// State of a station. False when the station has not been synced
+func (a *Archive) State(station string) (StationState, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.state.Stations[station]
+
+	if !ok {
+		return StationState{}, false
+	}
+
+	return *s, true
+}
+
+// Window is a query needed to catch stations up to now
+type Window struct {
+	Stations []string
+	Start    time.Time
+	End      time.Time
+}
+
+// Query builds the weather data query of the window
+func (w Window) Query(data []iem.WeatherDataData) *iem.WeatherDataQueryBuilder {
+	return iem.NewWeatherDataQuery().
+		Stations(w.Stations...).
+		Data(data...).
+		Start(w.Start).
+		End(w.End)
+}
+
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Windows are the fewest queries that catch stations up to now. Stations last
+// observed on the same day share a window, which starts on that day and ends
+// tomorrow (UTC) since query end dates are exclusive
+func (a *Archive) Windows(stations []string, now time.Time) []Window {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	end := day(now).AddDate(0, 0, 1)
+	byStart := map[time.Time]*Window{}
+
+	for _, station := range stations {
+		start := day(a.Since)
+
+		if s, ok := a.state.Stations[station]; ok && !s.LastObservation.IsZero() {
+			start = day(s.LastObservation)
+		}
+
+		w, ok := byStart[start]
+
+		if !ok {
+			w = &Window{Start: start, End: end}
+			byStart[start] = w
+		}
+
+		w.Stations = append(w.Stations, station)
+	}
+
+	windows := []Window{}
+
+	for _, w := range byStart {
+		windows = append(windows, *w)
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	return windows
+}
+
+// Sync requests the data columns of stations from IEM since their last archived
+// observation and appends the new observations. Rows overlapping already
+// archived observations are skipped. Stations must always be synced with the
+// same data columns, syncing an archived station with others is an error.
+//
+// Observations are keyed by station and time. IEM can report a routine (type 3)
+// and a special (type 4) observation in the same minute, but IEMWeatherData has
+// no report type to tell them apart, so only the last row of a station and
+// time in a response is archived
+func (a *Archive) Sync(ctx context.Context, stations []string, data []iem.WeatherDataData) error {
+	now := time.Now()
+
+	for _, w := range a.Windows(stations, now) {
+		observations, err := a.weather.Get(ctx, w.Query(data))
+
+		if err != nil {
+			return err
+		}
+
+		byStation := map[string][]*iem.IEMWeatherData{}
+
+		for _, o := range observations {
+			if o.Time != nil {
+				byStation[o.Station] = append(byStation[o.Station], o)
+			}
+		}
+
+		for _, station := range w.Stations {
+			if err := a.append(station, byStation[station], data, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *Archive) stationPath(station string) string {
+	return filepath.Join(a.dir, strings.ToUpper(station)+".csv")
+}
+
+// Query archived observations are written and read with. Times are UTC and
+// missing values and traces use asos.py's default M and T
+func archiveQuery(data []iem.WeatherDataData) *iem.WeatherDataQueryBuilder {
+	return iem.NewWeatherDataQuery().Data(data...)
+}
+
+// Header of a station's archive file. Empty when the station has no archived observations
+func (a *Archive) header(station string) ([]byte, error) {
+	file, err := os.Open(a.stationPath(station))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+
+	if err == io.EOF {
+		return line, nil
+	}
+
+	return line, err
+}
+
+func (a *Archive) append(station string, observations []*iem.IEMWeatherData, data []iem.WeatherDataData, now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.state.Stations[station]
+
+	if !ok {
+		state = &StationState{}
+		a.state.Stations[station] = state
+	}
+
+	sort.SliceStable(observations, func(i, j int) bool {
+		return observations[i].Time.Before(*observations[j].Time)
+	})
+
+	// The sort is stable so the last row of a time replaces the earlier ones
+	unique := []*iem.IEMWeatherData{}
+
+	for _, o := range observations {
+		if n := len(unique); n > 0 && unique[n-1].Time.Equal(*o.Time) {
+			unique[n-1] = o
+			continue
+		}
+
+		unique = append(unique, o)
+	}
+
+	added := []*iem.IEMWeatherData{}
+
+	for _, o := range unique {
+		if o.Time.After(state.LastObservation) {
+			added = append(added, o)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := iem.WriteWeatherData(&buf, added, archiveQuery(data)); err != nil {
+		return err
+	}
+
+	header, err := a.header(station)
+
+	if err != nil {
+		return err
+	}
+
+	body := buf.Bytes()
+
+	// Rows are appended under the header already in the file
+	if len(header) > 0 {
+		newHeader, rows, _ := bytes.Cut(body, []byte("\n"))
+
+		if string(header) != string(newHeader)+"\n" {
+			return fmt.Errorf("archive of %s has columns %q, synced with %q", station, strings.TrimSpace(string(header)), newHeader)
+		}
+
+		body = rows
+	}
+
+	file, err := os.OpenFile(a.stationPath(station), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(body); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if len(added) > 0 {
+		state.LastObservation = *added[len(added)-1].Time
+	}
+
+	state.LastSync = now
+
+	return a.saveState()
+}
+
+// Writes the state to a temporary file and renames it so a failed write never
+// loses the previous state
+func (a *Archive) saveState() error {
+	body, err := json.MarshalIndent(a.state, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(a.dir, stateFile)
+	tmp := path + ".tmp"
+
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Read gets the archived observations of a station from start (inclusive) to
+// end (exclusive). A zero start or end leaves that side of the range open
+func (a *Archive) Read(station string, start time.Time, end time.Time) ([]*iem.IEMWeatherData, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data := []*iem.IEMWeatherData{}
+
+	file, err := os.Open(a.stationPath(station))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The reader closes the file
+	reader := iem.NewWeatherDataReader(file, archiveQuery(nil))
+	defer reader.Close()
+
+	for {
+		d, err := reader.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Time == nil || (!start.IsZero() && d.Time.Before(start)) {
+			continue
+		}
+
+		// Observations are in time order
+		if !end.IsZero() && !d.Time.Before(end) {
+			break
+		}
+
+		data = append(data, d)
+	}
+
+	return data, nil
+}
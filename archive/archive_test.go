@@ -0,0 +1,194 @@
+package archive
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+	"github.com/colevoss/go-iem-sdk/iemmock"
+)
+
+func TestSync(t *testing.T) {
+	file, err := os.Open("../data/full_weather_data.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := iem.ParseWeatherData(file, iem.NewWeatherDataQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weather := iemmock.NewWeatherService()
+	weather.Return(data...)
+	since := time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+
+	archive, err := Open(dir, weather, since)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	columns := []iem.WeatherDataData{iem.TempF}
+
+	if err := archive.Sync(ctx, []string{"LNK"}, columns); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second sync requests the last observed day again and skips the overlap
+	if err := archive.Sync(ctx, []string{"LNK"}, columns); err != nil {
+		t.Fatal(err)
+	}
+
+	weather.AssertCalled(t, 2)
+	weather.AssertCalledWithStations(t, "LNK")
+	weather.AssertCalledWith(t, "data", "tmpf")
+
+	queries := weather.Queries()
+
+	if len(queries) != 2 {
+		t.FailNow()
+	}
+
+	v, _ := queries[1].BuildUrl()
+
+	if v.Get("day1") != "4" || v.Get("month1") != "10" {
+		t.Errorf("expected second window to start on the last observed day, got %s", v.Encode())
+	}
+
+	reopened, err := Open(dir, weather, since)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, ok := reopened.State("LNK")
+
+	if !ok || !state.LastObservation.Equal(*data[len(data)-1].Time) {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	all, err := reopened.Read("LNK", time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != len(data) {
+		t.Errorf("expected %d deduplicated observations, got %d", len(data), len(all))
+	}
+
+	start := time.Date(2023, 10, 4, 1, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 10, 4, 2, 0, 0, 0, time.UTC)
+
+	hour, err := reopened.Read("LNK", start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hour) != 6 {
+		t.Errorf("expected 6 observations in range, got %d", len(hour))
+	}
+}
+
+func TestSyncKeepsLastObservationOfMinute(t *testing.T) {
+	valid := time.Date(2023, 10, 4, 1, 54, 0, 0, time.UTC)
+	routine := &iem.IEMWeatherData{Station: "LNK", Time: &valid}
+	routine.SetFloat(iem.TempF, 50)
+	special := &iem.IEMWeatherData{Station: "LNK", Time: &valid}
+	special.SetFloat(iem.TempF, 48)
+
+	weather := iemmock.NewWeatherService()
+	weather.Return(routine, special)
+
+	archive, err := Open(t.TempDir(), weather, valid)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.Sync(context.Background(), []string{"LNK"}, []iem.WeatherDataData{iem.TempF}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := archive.Read("LNK", time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(all))
+	}
+
+	if temp, _ := all[0].Float(iem.TempF); temp != 48 {
+		t.Errorf("expected the last observation of the minute (48), got %v", temp)
+	}
+}
+
+func TestReadKeepsZerosAndTraces(t *testing.T) {
+	query := iem.NewWeatherDataQuery().Data(iem.TempF, iem.PrecipInch, iem.WindGustKnots)
+	body := "station,valid,tmpf,p01i,gust\n" +
+		"LNK,2023-01-04 06:54,0.00,T,M\n" +
+		"LNK,2023-01-04 07:54,-1.00,0.00,25.00\n"
+
+	data, err := iem.ParseWeatherData(strings.NewReader(body), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weather := iemmock.NewWeatherService()
+	weather.Return(data...)
+	dir := t.TempDir()
+
+	archive, err := Open(dir, weather, *data[0].Time)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns := []iem.WeatherDataData{iem.TempF, iem.PrecipInch, iem.WindGustKnots}
+
+	// The second sync appends nothing under the existing header
+	for i := 0; i < 2; i++ {
+		if err := archive.Sync(context.Background(), []string{"LNK"}, columns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := archive.Read("LNK", time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 2 {
+		t.Fatalf("expected 2 observations, got %d", len(all))
+	}
+
+	first, second := all[0], all[1]
+
+	if first.IsMissing(iem.TempF) || !first.IsTrace(iem.PrecipInch) || !first.IsMissing(iem.WindGustKnots) {
+		t.Errorf("expected an observed 0F, trace precip and missing gust %+v", first)
+	}
+
+	if second.IsMissing(iem.PrecipInch) || second.IsTrace(iem.PrecipInch) || second.WindGustKnots != 25 {
+		t.Errorf("expected observed 0.00 precip %+v", second)
+	}
+
+	// Syncing with different columns would mix rows under the wrong header
+	if err := archive.Sync(context.Background(), []string{"LNK"}, []iem.WeatherDataData{iem.TempF}); err == nil {
+		t.Error("expected an error syncing different columns")
+	}
+}
@@ -0,0 +1,286 @@
+package iem
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NeverExpire is the TTL of cached responses that never change
+const NeverExpire time.Duration = -1
+
+// Cache stores IEM responses by their normalized request URL
+type Cache interface {
+	Get(key string) ([]byte, bool)
+
+	// Stores a value for ttl. Values stored with NeverExpire are kept until deleted or evicted
+	Set(key string, value []byte, ttl time.Duration)
+
+	Delete(key string)
+}
+
+// CachePolicy sets how long responses are cached by endpoint class. A zero
+// TTL does not cache the endpoint class
+type CachePolicy struct {
+	Networks time.Duration // Network list (/api/1/networks.json)
+	Stations time.Duration // Station lists and metadata (/api/1/network/, /api/1/station/)
+
+	// Date window requests (asos.py, daily.py, etc) ending before today never
+	// change and are cached with NeverExpire, windows including today use Current
+	Current time.Duration
+
+	Default time.Duration // Any other request
+}
+
+// DefaultCachePolicy caches station metadata for a day and current date
+// windows for five minutes. Other requests are not cached
+func DefaultCachePolicy() CachePolicy {
+	return CachePolicy{
+		Networks: 24 * time.Hour,
+		Stations: 24 * time.Hour,
+		Current:  5 * time.Minute,
+	}
+}
+
+// TTL of the response of a request url. False when it should not be cached
+func (p CachePolicy) TTL(requestUrl string, now time.Time) (time.Duration, bool) {
+	u, err := url.Parse(requestUrl)
+
+	if err != nil {
+		return 0, false
+	}
+
+	var ttl time.Duration
+
+	switch {
+	case strings.HasSuffix(u.Path, "/api/1/networks.json"):
+		ttl = p.Networks
+
+	case strings.Contains(u.Path, "/api/1/network/"), strings.Contains(u.Path, "/api/1/station/"):
+		ttl = p.Stations
+
+	default:
+		end, ok := windowEnd(u.Query())
+
+		if !ok {
+			ttl = p.Default
+			break
+		}
+
+		// Allow a day for the query's timezone before a window is complete
+		if end.AddDate(0, 0, 1).Before(now) {
+			return NeverExpire, true
+		}
+
+		ttl = p.Current
+	}
+
+	return ttl, ttl != 0
+}
+
+// End date of a year2/month2/day2 date window query
+func windowEnd(v url.Values) (time.Time, bool) {
+	parts := make([]int, 3)
+
+	for i, key := range []string{"year2", "month2", "day2"} {
+		n, err := strconv.Atoi(v.Get(key))
+
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		parts[i] = n
+	}
+
+	return time.Date(parts[0], time.Month(parts[1]), parts[2], 0, 0, 0, 0, time.UTC), true
+}
+
+// NormalizeURL sorts the query keys and the values of each key of a request
+// url so requests built in any order have the same cache key
+func NormalizeURL(requestUrl string) string {
+	u, err := url.Parse(requestUrl)
+
+	if err != nil {
+		return requestUrl
+	}
+
+	v := u.Query()
+
+	for _, values := range v {
+		sort.Strings(values)
+	}
+
+	u.RawQuery = v.Encode()
+
+	return u.String()
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // Zero when the entry never expires
+}
+
+// MemoryCache is an in memory least recently used Cache
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // Most recently used at the front
+}
+
+// Creates a MemoryCache holding up to capacity responses
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl == NeverExpire {
+		return time.Time{}
+	}
+
+	return time.Now().Add(ttl)
+}
+
+func expired(expires time.Time) bool {
+	return !expires.IsZero() && time.Now().After(expires)
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryCacheEntry)
+
+	if expired(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	entry := &memoryCacheEntry{key: key, value: value, expires: expiresAt(ttl)}
+	c.entries[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// DiskCache is a Cache storing each response in a file of a directory. The
+// file starts with the expiration time of the response
+type DiskCache struct {
+	dir string
+}
+
+// Creates a DiskCache in dir, creating it when it does not exist
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	file, err := os.ReadFile(c.path(key))
+
+	if err != nil || len(file) < 8 {
+		return nil, false
+	}
+
+	var expires time.Time
+
+	if unix := int64(binary.BigEndian.Uint64(file[:8])); unix != 0 {
+		expires = time.Unix(0, unix)
+	}
+
+	if expired(expires) {
+		c.Delete(key)
+		return nil, false
+	}
+
+	return file[8:], true
+}
+
+// Set writes to a temporary file and renames it so readers never see a partial response
+func (c *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	header := make([]byte, 8)
+
+	if expires := expiresAt(ttl); !expires.IsZero() {
+		binary.BigEndian.PutUint64(header, uint64(expires.UnixNano()))
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(append(header, value...))
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
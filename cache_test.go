@@ -0,0 +1,155 @@
+package iem
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	a := NormalizeURL("https://example.com/asos.py?station=DSM&data=tmpf&station=AMW")
+	b := NormalizeURL("https://example.com/asos.py?data=tmpf&station=AMW&station=DSM")
+
+	if a != b {
+		t.Errorf("expected equal keys %s %s", a, b)
+	}
+}
+
+func TestCachePolicyTTL(t *testing.T) {
+	policy := DefaultCachePolicy()
+	now := time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	if ttl, ok := policy.TTL("/cgi-bin/request/asos.py?year2=2023&month2=10&day2=5", now); !ok || ttl != NeverExpire {
+		t.Errorf("expected historical window to never expire, got %v %v", ttl, ok)
+	}
+
+	if ttl, ok := policy.TTL("/cgi-bin/request/asos.py?year2=2023&month2=10&day2=10", now); !ok || ttl != policy.Current {
+		t.Errorf("expected current window ttl, got %v %v", ttl, ok)
+	}
+
+	if ttl, ok := policy.TTL("/api/1/networks.json", now); !ok || ttl != policy.Networks {
+		t.Errorf("expected networks ttl, got %v %v", ttl, ok)
+	}
+
+	if _, ok := policy.TTL("/json/raob.py?station=OAX", now); ok {
+		t.Error("expected default requests to not be cached")
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+
+	cache.Set("a", []byte("a"), NeverExpire)
+	cache.Set("b", []byte("b"), NeverExpire)
+	cache.Get("a")
+	cache.Set("c", []byte("c"), NeverExpire)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("expected recently used entry to be kept")
+	}
+
+	cache.Set("d", []byte("d"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if _, ok := cache.Get("d"); ok {
+		t.Error("expected expired entry")
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Set("key", []byte("value"), time.Hour)
+
+	if value, ok := cache.Get("key"); !ok || string(value) != "value" {
+		t.Errorf("unexpected cached value %q %v", value, ok)
+	}
+
+	cache.Delete("key")
+
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected deleted entry")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"data": [{"id": "IA_ASOS"}]}`))
+	}))
+
+	defer server.Close()
+
+	client := NewClientWithOptions(WithCache(NewMemoryCache(10), DefaultCachePolicy()))
+	client.baseUrl = server.URL
+
+	for i := 0; i < 3; i++ {
+		networks, err := client.Networks().GetNetworks(context.Background())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(networks) != 1 || networks[0].Id != "IA_ASOS" {
+			t.Fatalf("unexpected networks %v", networks)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestClientCacheSkipsUnparsedBodies(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		// asos.py reports errors as plain text with a 200
+		if requests == 1 {
+			w.Write([]byte("Unknown station provided: XXX\n"))
+			return
+		}
+
+		w.Write([]byte("station,valid,tmpf\nLNK,2023-10-04 00:54,79.00\n"))
+	}))
+
+	defer server.Close()
+
+	client := NewClientWithOptions(WithCache(NewMemoryCache(10), DefaultCachePolicy()), WithBaseUrl(server.URL))
+	start := time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)
+	query := NewWeatherDataQuery().Stations("LNK").Data(TempF).Start(start).End(start.AddDate(0, 0, 1))
+
+	if _, err := client.Weather().Get(context.Background(), query); err == nil {
+		t.Fatal("expected error body to fail to parse")
+	}
+
+	for i := 0; i < 2; i++ {
+		data, err := client.Weather().Get(context.Background(), query)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(data) != 1 || data[0].TemperatureF != 79 {
+			t.Fatalf("unexpected data %v", data)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("expected the error body to not be cached and 2 requests, got %d", requests)
+	}
+}
@@ -0,0 +1,230 @@
+// Package calc derives meteorological variables that IEM does not always
+// return from the values of IEMWeatherData observations
+package calc
+
+import (
+	"math"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+const knotsToMPH = 1.150779
+const inHgToMB = 33.8639
+
+// FToC converts Fahrenheit to Celsius
+func FToC(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// CToF converts Celsius to Fahrenheit
+func CToF(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// SaturationVaporPressure is the saturation vapor pressure over water [mb] at a temperature [F] (Bolton 1980)
+func SaturationVaporPressure(tempF float64) float64 {
+	c := FToC(tempF)
+	return 6.112 * math.Exp(17.67*c/(c+243.5))
+}
+
+// VaporPressure is the actual vapor pressure [mb] at a dew point [F]
+func VaporPressure(dewPointF float64) float64 {
+	return SaturationVaporPressure(dewPointF)
+}
+
+// RelativeHumidity [%] from temperature and dew point [F]
+func RelativeHumidity(tempF, dewPointF float64) float64 {
+	return math.Min(100, VaporPressure(dewPointF)/SaturationVaporPressure(tempF)*100)
+}
+
+// HeatIndex [F] using the NWS Rothfusz regression and its adjustments.
+// Below 80F the simple Steadman formula is used
+func HeatIndex(tempF, relh float64) float64 {
+	simple := 0.5 * (tempF + 61 + (tempF-68)*1.2 + relh*0.094)
+
+	if (simple+tempF)/2 < 80 {
+		return simple
+	}
+
+	t, rh := tempF, relh
+
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh - 0.22475541*t*rh -
+		0.00683783*t*t - 0.05481717*rh*rh + 0.00122874*t*t*rh +
+		0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	if rh < 13 && t >= 80 && t <= 112 {
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	} else if rh > 85 && t >= 80 && t <= 87 {
+		hi += (rh - 85) / 10 * (87 - t) / 5
+	}
+
+	return hi
+}
+
+// WindChill [F] using the 2001 NWS formula. False when the temperature is above
+// 50F or the wind is 3 mph or less, where wind chill is not defined
+func WindChill(tempF, windMPH float64) (float64, bool) {
+	if tempF > 50 || windMPH <= 3 {
+		return 0, false
+	}
+
+	v := math.Pow(windMPH, 0.16)
+
+	return 35.74 + 0.6215*tempF - 35.75*v + 0.4275*tempF*v, true
+}
+
+// FeelsLike [F] is the heat index when it is warm, wind chill when it is cold
+// and windy and the air temperature otherwise
+func FeelsLike(tempF, relh, windMPH float64) float64 {
+	if tempF >= 80 {
+		return HeatIndex(tempF, relh)
+	}
+
+	if wc, ok := WindChill(tempF, windMPH); ok {
+		return wc
+	}
+
+	return tempF
+}
+
+// WetBulb temperature [F] from temperature [F] and relative humidity [%] (Stull 2011)
+func WetBulb(tempF, relh float64) float64 {
+	t := FToC(tempF)
+
+	tw := t*math.Atan(0.151977*math.Sqrt(relh+8.313659)) +
+		math.Atan(t+relh) - math.Atan(relh-1.676331) +
+		0.00391838*math.Pow(relh, 1.5)*math.Atan(0.023101*relh) - 4.686035
+
+	return CToF(tw)
+}
+
+// StationPressure [inHg] from the altimeter setting [inHg] and station elevation [m]
+func StationPressure(altimeterInHg, elevationM float64) float64 {
+	return altimeterInHg * math.Pow((288-0.0065*elevationM)/288, 5.2561)
+}
+
+// DensityAltitude [ft] from station pressure [inHg], temperature and dew point [F]
+// using the virtual temperature
+func DensityAltitude(stationPressureInHg, tempF, dewPointF float64) float64 {
+	pressureMB := stationPressureInHg * inHgToMB
+	vapor := VaporPressure(dewPointF)
+	tempK := FToC(tempF) + 273.15
+
+	virtualK := tempK / (1 - vapor/pressureMB*(1-0.622))
+	virtualR := virtualK * 9 / 5
+
+	return 145442.16 * (1 - math.Pow(17.326*stationPressureInHg/virtualR, 0.235))
+}
+
+// Derived are the variables calculated from an observation. Properties are nil
+// when the values needed to calculate them were not observed
+type Derived struct {
+	RelativeHumidity        *float64 `json:"relh,omitempty"`                      // Relative Humidity [%]
+	HeatIndexF              *float64 `json:"heat_index,omitempty"`                // Heat Index [F]
+	WindChillF              *float64 `json:"wind_chill,omitempty"`                // Wind Chill [F]
+	FeelsLikeF              *float64 `json:"feel,omitempty"`                      // Feels Like Temperature [F]
+	WetBulbF                *float64 `json:"wet_bulb,omitempty"`                  // Wet Bulb Temperature [F]
+	VaporPressureMB         *float64 `json:"vapor_pressure,omitempty"`            // Vapor Pressure [mb]
+	SaturationVaporPressure *float64 `json:"saturation_vapor_pressure,omitempty"` // Saturation Vapor Pressure [mb]
+	StationPressureInHg     *float64 `json:"station_pressure,omitempty"`          // Station Pressure [inHg]
+	DensityAltitudeFt       *float64 `json:"density_altitude,omitempty"`          // Density Altitude [ft]
+}
+
+func value(v float64) *float64 {
+	return &v
+}
+
+// Value of a column. False when it is missing
+func column(d *iem.IEMWeatherData, c iem.WeatherDataData) (float64, bool) {
+	if d.IsMissing(c) {
+		return 0, false
+	}
+
+	return d.Float(c)
+}
+
+// Wind speed [mph] from either sped or sknt. A speed of 0 in one is only used
+// when the other has no speed, since observations created by hand often only
+// set one of them
+func windMPH(d *iem.IEMWeatherData) (float64, bool) {
+	mph, hasMPH := column(d, iem.WindSpeedMPH)
+	knots, hasKnots := column(d, iem.WindSpeedKnots)
+
+	switch {
+	case hasMPH && mph != 0:
+		return mph, true
+	case hasKnots && knots != 0:
+		return knots * knotsToMPH, true
+	}
+
+	return 0, hasMPH || hasKnots
+}
+
+// Derive calculates the derived variables of an observation. Station pressure
+// and density altitude need the station's elevation, station may be nil.
+// Variables are not calculated when a value they need is missing
+func Derive(d *iem.IEMWeatherData, station *iem.Station) *Derived {
+	derived := &Derived{}
+
+	temp, hasTemp := column(d, iem.TempF)
+	dewPoint, hasDewPoint := column(d, iem.DewPointF)
+	relh, hasRelh := column(d, iem.RelativeHumidity)
+
+	if hasTemp && hasDewPoint {
+		relh, hasRelh = RelativeHumidity(temp, dewPoint), true
+	}
+
+	if hasRelh {
+		derived.RelativeHumidity = value(relh)
+	}
+
+	if hasDewPoint {
+		derived.VaporPressureMB = value(VaporPressure(dewPoint))
+	}
+
+	if !hasTemp {
+		return derived
+	}
+
+	derived.SaturationVaporPressure = value(SaturationVaporPressure(temp))
+
+	// A missing wind has no wind chill, so feels like is the air temperature
+	wind, _ := windMPH(d)
+
+	if wc, ok := WindChill(temp, wind); ok {
+		derived.WindChillF = value(wc)
+	}
+
+	if hasRelh {
+		derived.HeatIndexF = value(HeatIndex(temp, relh))
+		derived.FeelsLikeF = value(FeelsLike(temp, relh, wind))
+		derived.WetBulbF = value(WetBulb(temp, relh))
+	}
+
+	if altimeter, ok := column(d, iem.Altimeter); ok && station != nil {
+		pressure := StationPressure(altimeter, station.Elevation)
+		derived.StationPressureInHg = value(pressure)
+
+		if hasDewPoint {
+			derived.DensityAltitudeFt = value(DensityAltitude(pressure, temp, dewPoint))
+		}
+	}
+
+	return derived
+}
+
+// Fill sets the RelativeHumidity and Feel of observations that are missing
+// them when they can be calculated
+func Fill(data []*iem.IEMWeatherData) {
+	for _, d := range data {
+		derived := Derive(d, nil)
+
+		if d.IsMissing(iem.RelativeHumidity) && derived.RelativeHumidity != nil {
+			d.SetFloat(iem.RelativeHumidity, *derived.RelativeHumidity)
+		}
+
+		if d.IsMissing(iem.Feel) && derived.FeelsLikeF != nil {
+			d.SetFloat(iem.Feel, *derived.FeelsLikeF)
+		}
+	}
+}
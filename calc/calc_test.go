@@ -0,0 +1,101 @@
+package calc
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+func assertClose(t *testing.T, name string, got, expected, tolerance float64) {
+	t.Helper()
+
+	if math.Abs(got-expected) > tolerance {
+		t.Errorf("%s: expected %f got %f", name, expected, got)
+	}
+}
+
+func TestFormulas(t *testing.T) {
+	// Values from the NWS heat index and wind chill charts
+	assertClose(t, "heat index", HeatIndex(90, 70), 106, 1)
+	assertClose(t, "heat index low humidity", HeatIndex(100, 10), 95, 1)
+
+	wc, ok := WindChill(0, 15)
+
+	if !ok {
+		t.Fatal("expected wind chill")
+	}
+
+	assertClose(t, "wind chill", wc, -19, 0.5)
+
+	if _, ok := WindChill(60, 15); ok {
+		t.Error("expected no wind chill above 50F")
+	}
+
+	assertClose(t, "relative humidity", RelativeHumidity(80, 60), 50.6, 0.5)
+	assertClose(t, "saturated", RelativeHumidity(50, 50), 100, 0.001)
+
+	// Stull 2011: 20C and 50% has a wet bulb of 13.7C
+	assertClose(t, "wet bulb", FToC(WetBulb(68, 50)), 13.7, 0.1)
+
+	assertClose(t, "station pressure sea level", StationPressure(29.92, 0), 29.92, 0.001)
+	assertClose(t, "station pressure 1000m", StationPressure(29.92, 1000), 26.54, 0.05)
+
+	// A dry standard atmosphere at sea level has a density altitude of ~0 ft
+	assertClose(t, "density altitude", DensityAltitude(29.92, 59, -100), 0, 50)
+}
+
+func TestDeriveAndFill(t *testing.T) {
+	station := &iem.Station{Elevation: 352}
+	d := &iem.IEMWeatherData{TemperatureF: 79, DewPointF: 58, Altimeter: 29.73, WindSpeedKnots: 19}
+
+	derived := Derive(d, station)
+
+	if derived.RelativeHumidity == nil || derived.StationPressureInHg == nil || derived.DensityAltitudeFt == nil {
+		t.Fatalf("expected derived values %+v", derived)
+	}
+
+	assertClose(t, "relh", *derived.RelativeHumidity, 48.59, 0.5)
+
+	if derived.WindChillF != nil {
+		t.Error("expected no wind chill")
+	}
+
+	Fill([]*iem.IEMWeatherData{d})
+
+	if d.RelativeHumidity == 0 || d.Feel != 79 {
+		t.Errorf("expected filled relh and feel, got %f %f", d.RelativeHumidity, d.Feel)
+	}
+}
+
+func TestDeriveZeroTemperature(t *testing.T) {
+	query := iem.NewWeatherDataQuery()
+	body := "station,valid,tmpf,dwpf,sknt\nLNK,2023-01-04 06:54,0.00,-9.00,20.00\n"
+	data, err := iem.ParseWeatherData(strings.NewReader(body), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derived := Derive(data[0], nil)
+
+	if derived.WindChillF == nil || derived.FeelsLikeF == nil || derived.RelativeHumidity == nil {
+		t.Fatalf("expected derived values at 0F %+v", derived)
+	}
+
+	wc, _ := WindChill(0, 20*knotsToMPH)
+	assertClose(t, "wind chill", *derived.WindChillF, wc, 0.01)
+	assertClose(t, "feels like", *derived.FeelsLikeF, wc, 0.01)
+
+	// Missing wind has no wind chill
+	missing, err := iem.ParseWeatherData(strings.NewReader("station,valid,tmpf,sknt\nLNK,2023-01-04 06:54,0.00,M\n"), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if derived := Derive(missing[0], nil); derived.WindChillF != nil || derived.SaturationVaporPressure == nil {
+		t.Errorf("expected no wind chill with a missing wind %+v", derived)
+	}
+}
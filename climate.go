@@ -0,0 +1,210 @@
+package iem
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ClimateDay is the climatology of a station for a day of the year. Averages
+// are the normals and min/max values are the records over the period
+type ClimateDay struct {
+	Month int `json:"month"`
+	Day   int `json:"day"`
+	Years int `json:"years"` // Years of data
+
+	HighF float64 `json:"high"` // Average High Temperature [F]
+	LowF  float64 `json:"low"`  // Average Low Temperature [F]
+
+	RecordHighF            float64 `json:"max_high"`       // Record High Temperature [F]
+	RecordHighYears        []int   `json:"max_high_years"` // Years the record high was set
+	RecordColdestHighF     float64 `json:"min_high"`       // Record Coldest High Temperature [F]
+	RecordColdestHighYears []int   `json:"min_high_years"` // Years the record coldest high was set
+	RecordLowF             float64 `json:"min_low"`        // Record Low Temperature [F]
+	RecordLowYears         []int   `json:"min_low_years"`  // Years the record low was set
+	RecordWarmestLowF      float64 `json:"max_low"`        // Record Warmest Low Temperature [F]
+	RecordWarmestLowYears  []int   `json:"max_low_years"`  // Years the record warmest low was set
+
+	PrecipInch        float64 `json:"precip"`           // Average Precipitation [inch]
+	RecordPrecipInch  float64 `json:"max_precip"`       // Record Precipitation [inch]
+	RecordPrecipYears []int   `json:"max_precip_years"` // Years the record precipitation was set
+}
+
+// Climatology is the daily climatology of a climodat station
+type Climatology struct {
+	Station   string        `json:"station"`
+	StartYear int           `json:"start_year,omitempty"` // First year included, 0 for the period of record
+	EndYear   int           `json:"end_year,omitempty"`   // Last year included, 0 for the period of record
+	Days      []*ClimateDay `json:"climatology"`
+}
+
+// On returns the climatology for the month and day of t
+func (c *Climatology) On(t time.Time) (*ClimateDay, bool) {
+	return c.Day(t.Month(), t.Day())
+}
+
+// Day returns the climatology for a month and day
+func (c *Climatology) Day(month time.Month, day int) (*ClimateDay, bool) {
+	for _, d := range c.Days {
+		if d.Month == int(month) && d.Day == day {
+			return d, true
+		}
+	}
+
+	return nil, false
+}
+
+// ClimateQuery selects the station and years used to compute a climatology.
+// Zero years use the station's whole period of record
+type ClimateQuery struct {
+	Station   string // Climodat station id (IATDSM)
+	StartYear int
+	EndYear   int
+}
+
+type ClimateService interface {
+	GetClimatology(ctx context.Context, query ClimateQuery) (*Climatology, error)
+
+	// Gets the period of record climatology of a station's climate site
+	GetStationClimatology(ctx context.Context, station *Station) (*Climatology, error)
+}
+
+type IEMClimateService struct {
+	client *Client
+}
+
+func (s *IEMClimateService) GetClimatology(ctx context.Context, query ClimateQuery) (*Climatology, error) {
+	v := url.Values{}
+	v.Add("station", query.Station)
+
+	if query.StartYear != 0 {
+		v.Add("syear", strconv.Itoa(query.StartYear))
+	}
+
+	if query.EndYear != 0 {
+		v.Add("eyear", strconv.Itoa(query.EndYear))
+	}
+
+	url := fmt.Sprintf("/json/climodat_stclimo.py?%s", v.Encode())
+	climatology := &Climatology{}
+
+	err := s.client.getJson(ctx, url, climatology)
+
+	if err != nil {
+		return nil, err
+	}
+
+	climatology.Station = query.Station
+	climatology.StartYear = query.StartYear
+	climatology.EndYear = query.EndYear
+
+	return climatology, nil
+}
+
+func (s *IEMClimateService) GetStationClimatology(ctx context.Context, station *Station) (*Climatology, error) {
+	if station.ClimateSite == "" {
+		return nil, IEMNotFoundError{
+			Detail: fmt.Sprintf("station %s has no climate site", station.Id),
+			Code:   404,
+		}
+	}
+
+	return s.GetClimatology(ctx, ClimateQuery{Station: station.ClimateSite})
+}
+
+// ClimateAnnotation compares an observation or daily summary with the
+// climatology of its day. Departures are nil when the value was not observed
+type ClimateAnnotation struct {
+	Station string      `json:"station"`
+	Time    time.Time   `json:"time"`
+	Normal  *ClimateDay `json:"normal"`
+
+	TemperatureDepartureF *float64 `json:"tmpf_departure,omitempty"`   // Observed minus average daily mean temperature [F]
+	HighDepartureF        *float64 `json:"high_departure,omitempty"`   // Observed minus average high temperature [F]
+	LowDepartureF         *float64 `json:"low_departure,omitempty"`    // Observed minus average low temperature [F]
+	PrecipDepartureInch   *float64 `json:"precip_departure,omitempty"` // Observed minus average precipitation [inch]
+
+	RecordHigh   bool `json:"record_high"`   // Above the record high
+	RecordLow    bool `json:"record_low"`    // Below the record low
+	RecordPrecip bool `json:"record_precip"` // Above the record precipitation
+}
+
+func departure(observed, normal float64) *float64 {
+	d := observed - normal
+	return &d
+}
+
+// AnnotateWeatherData compares the temperature of observations at the
+// climatology's station with the normal daily mean and records. Observations
+// without a time or with a missing temperature are skipped
+func (c *Climatology) AnnotateWeatherData(data []*IEMWeatherData) []*ClimateAnnotation {
+	annotations := []*ClimateAnnotation{}
+
+	for _, d := range data {
+		if d.Time == nil || d.IsMissing(TempF) {
+			continue
+		}
+
+		normal, ok := c.On(*d.Time)
+
+		if !ok {
+			continue
+		}
+
+		annotations = append(annotations, &ClimateAnnotation{
+			Station:               d.Station,
+			Time:                  *d.Time,
+			Normal:                normal,
+			TemperatureDepartureF: departure(d.TemperatureF, (normal.HighF+normal.LowF)/2),
+			RecordHigh:            d.TemperatureF > normal.RecordHighF,
+			RecordLow:             d.TemperatureF < normal.RecordLowF,
+		})
+	}
+
+	return annotations
+}
+
+// AnnotateDaily compares COOP daily summaries at the climatology's station
+// with the normals and records of their day. Departures of missing values are nil
+func (c *Climatology) AnnotateDaily(data []*COOPDailyData) []*ClimateAnnotation {
+	annotations := []*ClimateAnnotation{}
+
+	for _, d := range data {
+		if d.Date == nil {
+			continue
+		}
+
+		normal, ok := c.On(*d.Date)
+
+		if !ok {
+			continue
+		}
+
+		annotation := &ClimateAnnotation{
+			Station: d.Station,
+			Time:    *d.Date,
+			Normal:  normal,
+		}
+
+		if !d.IsMissing(COOPPrecipInch) {
+			annotation.PrecipDepartureInch = departure(d.PrecipInch, normal.PrecipInch)
+			annotation.RecordPrecip = d.PrecipInch > normal.RecordPrecipInch
+		}
+
+		if !d.IsMissing(COOPHighF) {
+			annotation.HighDepartureF = departure(d.HighF, normal.HighF)
+			annotation.RecordHigh = d.HighF > normal.RecordHighF
+		}
+
+		if !d.IsMissing(COOPLowF) {
+			annotation.LowDepartureF = departure(d.LowF, normal.LowF)
+			annotation.RecordLow = d.LowF < normal.RecordLowF
+		}
+
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations
+}
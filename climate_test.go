@@ -0,0 +1,165 @@
+package iem
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadClimatologyFixture(t *testing.T) *Climatology {
+	t.Helper()
+
+	body, err := os.ReadFile("./data/climodat_stclimo.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+
+	defer server.Close()
+
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+	climatology, err := client.Climate().GetClimatology(context.Background(), ClimateQuery{Station: "NE5000"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return climatology
+}
+
+func assertDeparture(t *testing.T, name string, departure *float64, expected float64) {
+	t.Helper()
+
+	if departure == nil || math.Abs(*departure-expected) > 0.001 {
+		t.Errorf("%s departure = %v, want %f", name, departure, expected)
+	}
+}
+
+func TestAnnotateWeatherData(t *testing.T) {
+	climatology := loadClimatologyFixture(t)
+	observations := loadWeatherFixture(t, "./data/full_weather_data.csv", NewWeatherDataQuery())
+
+	annotations := climatology.AnnotateWeatherData(observations)
+
+	if len(annotations) != len(observations) {
+		t.Fatalf("expected %d annotations, got %d", len(observations), len(annotations))
+	}
+
+	// 79F against a normal daily mean of (71.6 + 45.4) / 2
+	first := annotations[0]
+	assertDeparture(t, "tmpf", first.TemperatureDepartureF, 79-58.5)
+
+	if first.Station != "LNK" || first.Normal.Month != 10 || first.Normal.Day != 4 || first.RecordHigh || first.RecordLow {
+		t.Errorf("unexpected annotation %+v", first)
+	}
+}
+
+func TestAnnotateWeatherDataZeroAndMissing(t *testing.T) {
+	climatology := loadClimatologyFixture(t)
+	query := NewWeatherDataQuery().Data(TempF)
+	observations, err := ParseWeatherData(strings.NewReader("station,valid,tmpf\nLNK,2023-01-04 06:54,0.00\nLNK,2023-01-04 07:54,M\nLNK,2023-01-04 08:54,-27.00\n"), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := climatology.AnnotateWeatherData(observations)
+
+	// The missing temperature is skipped and 0F is observed
+	if len(annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(annotations))
+	}
+
+	assertDeparture(t, "0F", annotations[0].TemperatureDepartureF, 0-(32.1+12.6)/2)
+
+	if annotations[0].RecordLow || !annotations[1].RecordLow {
+		t.Errorf("expected only -27F to be a record low %+v %+v", annotations[0], annotations[1])
+	}
+}
+
+func TestAnnotateDaily(t *testing.T) {
+	climatology := loadClimatologyFixture(t)
+	file, err := os.Open("./data/coop_daily.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := ParseCOOPDailyData(file, NewCOOPQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := climatology.AnnotateDaily(data)
+
+	if len(annotations) != len(data) {
+		t.Fatalf("expected %d annotations, got %d", len(data), len(annotations))
+	}
+
+	first := annotations[0]
+	assertDeparture(t, "high", first.HighDepartureF, 31-32.4)
+	assertDeparture(t, "low", first.LowDepartureF, 22-12.9)
+	assertDeparture(t, "precip", first.PrecipDepartureInch, 0.42-0.02)
+
+	if first.RecordHigh || first.RecordLow || first.RecordPrecip {
+		t.Errorf("unexpected records %+v", first)
+	}
+
+	// A trace of precipitation is observed
+	assertDeparture(t, "trace precip", annotations[1].PrecipDepartureInch, 0.0001-0.02)
+
+	// The missing high has no departure
+	missingHigh := annotations[2]
+
+	if missingHigh.HighDepartureF != nil || missingHigh.RecordHigh {
+		t.Errorf("expected no high departure, got %+v", missingHigh)
+	}
+
+	assertDeparture(t, "low with missing high", missingHigh.LowDepartureF, 15-12.4)
+}
+
+func TestAnnotateDailyZeroAndMissing(t *testing.T) {
+	climatology := loadClimatologyFixture(t)
+	data, err := ParseCOOPDailyData(strings.NewReader("station,day,max_temp_f,min_temp_f,precip_in\nIATAME,2023-01-05,12,0,M\n"), NewCOOPQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotations := climatology.AnnotateDaily(data)
+
+	if len(annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(annotations))
+	}
+
+	annotation := annotations[0]
+
+	// 0F is an observed low and missing precipitation has no departure
+	assertDeparture(t, "0F low", annotation.LowDepartureF, 0-12.4)
+
+	if annotation.PrecipDepartureInch != nil || annotation.RecordPrecip {
+		t.Errorf("expected no precip departure, got %+v", annotation)
+	}
+
+	// Data created by hand has no parsed state, so a zero low is missing and zero precipitation is not
+	hand := climatology.AnnotateDaily([]*COOPDailyData{{Station: "IATAME", Date: data[0].Date, HighF: 20}})[0]
+
+	if hand.LowDepartureF != nil || hand.PrecipDepartureInch == nil {
+		t.Errorf("unexpected departures of data created by hand %+v", hand)
+	}
+
+	if !data[0].IsMissing(COOPPrecipInch) || !data[0].IsMissing(COOPSnowInch) || data[0].IsMissing(COOPLowF) {
+		t.Errorf("expected missing and unrequested variables to be missing")
+	}
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"sort"
+	"strconv"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(dateLayout)
+}
+
+var networkHeader = []string{"id", "name", "tzname", "windrose_update"}
+
+func networkRow(n *iem.Network) []string {
+	return []string{n.Id, n.Name, n.Tz, formatDate(n.WindroseUpdate)}
+}
+
+var stationHeader = []string{
+	"id", "network", "name", "state", "county", "latitude", "longitude", "elevation", "tzname", "online", "archive_begin",
+}
+
+func stationRow(s *iem.Station) []string {
+	return []string{
+		s.Id,
+		s.Network,
+		s.Name,
+		s.State,
+		s.County,
+		formatFloat(s.Latitude),
+		formatFloat(s.Longitude),
+		formatFloat(s.Elevation),
+		s.Timezone,
+		strconv.FormatBool(s.Online),
+		formatDate(s.ArchiveBegins),
+	}
+}
+
+func noArgs(args []string) error {
+	if len(args) > 0 {
+		return usagef("unexpected arguments %v", args)
+	}
+
+	return nil
+}
+
+func runNetworks(ctx context.Context, e *env, args []string) error {
+	var common commonFlags
+	fs := newFlagSet("networks", e, &common)
+
+	args, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if err := noArgs(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+
+	networks, err := e.client.Networks().GetNetworks(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return writeRecords(e.stdout, common.format, networks, networkHeader, networkRow)
+}
+
+func runStations(ctx context.Context, e *env, args []string) error {
+	var common commonFlags
+	fs := newFlagSet("stations", e, &common)
+	network := fs.String("network", "", "Network to list stations of (required)")
+	online := fs.Bool("online", false, "Only list stations that are online")
+
+	args, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if err := noArgs(args); err != nil {
+		return err
+	}
+
+	if *network == "" {
+		return usagef("-network is required")
+	}
+
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+
+	stations, err := e.client.Stations().GetStations(ctx, *network)
+
+	if err != nil {
+		return err
+	}
+
+	if *online {
+		filtered := []*iem.Station{}
+
+		for _, s := range stations {
+			if s.Online {
+				filtered = append(filtered, s)
+			}
+		}
+
+		stations = filtered
+	}
+
+	return writeRecords(e.stdout, common.format, stations, stationHeader, stationRow)
+}
+
+func runStation(ctx context.Context, e *env, args []string) error {
+	var common commonFlags
+	fs := newFlagSet("station", e, &common)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: iem station [flags] <station id>\n"))
+		fs.PrintDefaults()
+	}
+
+	args, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return usagef("expected one station id")
+	}
+
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+
+	station, err := e.client.Stations().GetStation(ctx, args[0])
+
+	if err != nil {
+		return err
+	}
+
+	if common.format == formatJSON {
+		return writeJSON(e.stdout, station)
+	}
+
+	return writeRecords(e.stdout, common.format, []*iem.Station{station}, stationHeader, stationRow)
+}
+
+// Data columns the weather command accepts
+func weatherDataNames() map[iem.WeatherDataData]bool {
+	names := map[iem.WeatherDataData]bool{iem.All: true}
+
+	for _, c := range iem.NewWeatherDataQuery().Data(iem.All).Columns() {
+		names[c] = true
+	}
+
+	return names
+}
+
+// Builds a weather query from the weather command's arguments
+func weatherQuery(e *env, args []string) (*iem.WeatherDataQueryBuilder, *commonFlags, error) {
+	var common commonFlags
+	var stations, data listFlag
+	var start, end dateFlag
+	var reportTypes listFlag
+
+	fs := newFlagSet("weather", e, &common)
+	fs.Var(&stations, "station", "Station to get observations of (required, repeatable or comma separated)")
+	fs.Var(&data, "data", "Data column to request (repeatable or comma separated, default all)")
+	fs.Var(&start, "start", "First day to get observations of as YYYY-MM-DD (default today)")
+	fs.Var(&end, "end", "Day to get observations until as YYYY-MM-DD, not included (default the day after start)")
+	tz := fs.String("tz", "Etc/UTC", "Timezone of days and returned times")
+	latlon := fs.Bool("latlon", false, "Include station lon and lat")
+	elev := fs.Bool("elev", false, "Include station elevation")
+	missing := fs.String("missing", string(iem.MissingM), "Encoding of missing values requested from IEM and written as csv (M, null or empty)")
+	trace := fs.String("trace", string(iem.TraceT), "Encoding of trace values requested from IEM (T, null, empty or 0.0001)")
+	format := fs.String("format", string(iem.OnlyComma), "Format requested from IEM (onlycomma, comma, onlytdf or tdf). Output is set by -o")
+	fs.Var(&reportTypes, "report-type", "Report type to include (repeatable or comma separated, default 3,4)")
+
+	rest, err := parseFlags(fs, args)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := noArgs(rest); err != nil {
+		return nil, nil, err
+	}
+
+	if len(stations) == 0 {
+		return nil, nil, usagef("-station is required")
+	}
+
+	if len(data) == 0 {
+		data = listFlag{string(iem.All)}
+	}
+
+	names := weatherDataNames()
+	query := iem.NewWeatherDataQuery().Stations(stations...)
+
+	for _, d := range data {
+		if !names[iem.WeatherDataData(d)] {
+			return nil, nil, usagef("unknown data column %q", d)
+		}
+
+		query.Data(iem.WeatherDataData(d))
+	}
+
+	location, err := time.LoadLocation(*tz)
+
+	if err != nil {
+		return nil, nil, usagef("unknown timezone %q", *tz)
+	}
+
+	if start.t.IsZero() {
+		start.t = time.Now().In(location)
+	}
+
+	// asos.py does not include the end day
+	if end.t.IsZero() {
+		end.t = start.t.AddDate(0, 0, 1)
+	}
+
+	if !end.t.After(start.t) {
+		return nil, nil, usagef("-end must be after -start")
+	}
+
+	switch iem.WeatherDataQueryMissing(*missing) {
+	case iem.MissingM, iem.MissingNull, iem.MissingEmpty:
+	default:
+		return nil, nil, usagef("-missing must be M, null or empty")
+	}
+
+	switch iem.WeatherDataQueryTrace(*trace) {
+	case iem.TraceT, iem.TraceNull, iem.TraceEmpty, iem.TraceFloat:
+	default:
+		return nil, nil, usagef("-trace must be T, null, empty or 0.0001")
+	}
+
+	switch iem.WeatherDataQueryFormat(*format) {
+	case iem.OnlyComma, iem.Comma, iem.OnlyTDF, iem.TDF:
+	default:
+		return nil, nil, usagef("-format must be onlycomma, comma, onlytdf or tdf")
+	}
+
+	if len(reportTypes) == 0 {
+		reportTypes = listFlag{"3", "4"}
+	}
+
+	types, err := parseInts(reportTypes)
+
+	if err != nil {
+		return nil, nil, usagef("-report-type: %s", err)
+	}
+
+	query.
+		Start(start.t).
+		End(end.t).
+		Timezone(*tz).
+		LatLon(*latlon).
+		Elevation(*elev).
+		Missing(iem.WeatherDataQueryMissing(*missing)).
+		Trace(iem.WeatherDataQueryTrace(*trace)).
+		Format(iem.WeatherDataQueryFormat(*format)).
+		ReportType(types...)
+
+	return query, &common, nil
+}
+
+func runWeather(ctx context.Context, e *env, args []string) error {
+	query, common, err := weatherQuery(e, args)
+
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+
+	data, err := e.client.Weather().Get(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	// -format only changes how IEM responds, csv and table output is comma delimited
+	output := *query
+	output.Format(iem.OnlyComma)
+
+	switch common.format {
+	case formatJSON:
+		return writeJSON(e.stdout, data)
+	case formatNDJSON:
+		return writeNDJSON(e.stdout, data)
+	case formatCSV:
+		return iem.WriteWeatherData(e.stdout, data, &output)
+	}
+
+	var buf bytes.Buffer
+
+	if err := iem.WriteWeatherData(&buf, data, &output); err != nil {
+		return err
+	}
+
+	return csvToTable(e.stdout, buf.Bytes())
+}
+
+// A station and its distance from the point searched
+type nearestStation struct {
+	*iem.Station
+	DistanceKm float64 `json:"distance_km"`
+}
+
+func runNearest(ctx context.Context, e *env, args []string) error {
+	var common commonFlags
+	var networks listFlag
+
+	fs := newFlagSet("nearest", e, &common)
+	fs.Var(&networks, "network", "Network to search (required, repeatable or comma separated)")
+	lat := fs.Float64("lat", 0, "Latitude of the point (required)")
+	lon := fs.Float64("lon", 0, "Longitude of the point (required)")
+	limit := fs.Int("n", 5, "Number of stations to list")
+	online := fs.Bool("online", false, "Only search stations that are online")
+
+	args, err := parseFlags(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if err := noArgs(args); err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if len(networks) == 0 || !set["lat"] || !set["lon"] {
+		return usagef("-network, -lat and -lon are required")
+	}
+
+	if *lat < -90 || *lat > 90 || *lon < -180 || *lon > 180 {
+		return usagef("-lat must be within [-90, 90] and -lon within [-180, 180]")
+	}
+
+	if *limit < 1 {
+		return usagef("-n must be at least 1")
+	}
+
+	ctx, cancel := common.context(ctx)
+	defer cancel()
+
+	nearest := []*nearestStation{}
+
+	for _, network := range networks {
+		stations, err := e.client.Stations().GetStations(ctx, network)
+
+		if err != nil {
+			return err
+		}
+
+		for _, s := range stations {
+			if *online && !s.Online {
+				continue
+			}
+
+			nearest = append(nearest, &nearestStation{
+				Station:    s,
+				DistanceKm: iem.DistanceKm(*lat, *lon, s.Latitude, s.Longitude),
+			})
+		}
+	}
+
+	sort.SliceStable(nearest, func(i, j int) bool {
+		return nearest[i].DistanceKm < nearest[j].DistanceKm
+	})
+
+	if len(nearest) > *limit {
+		nearest = nearest[:*limit]
+	}
+
+	header := append([]string{"distance_km"}, stationHeader...)
+
+	return writeRecords(e.stdout, common.format, nearest, header, func(n *nearestStation) []string {
+		return append([]string{strconv.FormatFloat(n.DistanceKm, 'f', 1, 64)}, stationRow(n.Station)...)
+	})
+}
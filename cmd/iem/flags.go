@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// listFlag collects values from repeated flags and comma separated lists
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+// dateFlag parses dates as YYYY-MM-DD
+type dateFlag struct {
+	t time.Time
+}
+
+func (d *dateFlag) String() string {
+	if d.t.IsZero() {
+		return ""
+	}
+
+	return d.t.Format(dateLayout)
+}
+
+func (d *dateFlag) Set(value string) error {
+	t, err := time.Parse(dateLayout, value)
+
+	if err != nil {
+		return fmt.Errorf("expected date as YYYY-MM-DD")
+	}
+
+	d.t = t
+
+	return nil
+}
+
+// Flags every command takes
+type commonFlags struct {
+	format  outputFormat
+	timeout time.Duration
+}
+
+func newFlagSet(name string, e *env, common *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("iem "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+
+	common.format = formatTable
+	fs.Var(&common.format, "o", "Output format (table, json, ndjson or csv)")
+	fs.DurationVar(&common.timeout, "timeout", time.Minute, "Time limit for requests")
+
+	return fs
+}
+
+// Parses flags and returns the remaining arguments. Parse errors other than
+// -h are usage errors
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+
+		// The flag package has already printed the error and usage
+		return nil, usageError{msg: err.Error()}
+	}
+
+	return fs.Args(), nil
+}
+
+func (f *commonFlags) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, f.timeout)
+}
+
+func parseInts(values []string) ([]int, error) {
+	ints := make([]int, 0, len(values))
+
+	for _, v := range values {
+		i, err := strconv.Atoi(v)
+
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", v)
+		}
+
+		ints = append(ints, i)
+	}
+
+	return ints, nil
+}
@@ -0,0 +1,164 @@
+// Command iem queries the Iowa Environmental Mesonet with the SDK.
+//
+// Usage:
+//
+//	iem <command> [flags]
+//
+// Commands:
+//
+//	networks  List networks
+//	stations  List the stations of a network
+//	station   Show a station
+//	weather   Get ASOS weather observations
+//	nearest   Find the stations of networks nearest to a point
+//
+// Every command takes -o to choose the output format (table, json, ndjson or
+// csv) and -timeout to limit how long requests take.
+//
+// Exit codes:
+//
+//	0    Success
+//	1    Error
+//	2    Invalid usage
+//	3    Not found
+//	4    IEM could not be reached or timed out
+//	130  Interrupted
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitUnavailable = 4
+	exitInterrupted = 130
+)
+
+// usageError is returned for invalid commands, flags and arguments
+type usageError struct {
+	msg string
+}
+
+func (err usageError) Error() string {
+	return err.msg
+}
+
+func usagef(format string, args ...interface{}) usageError {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+var commands = []*command{
+	{name: "networks", usage: "List networks", run: runNetworks},
+	{name: "stations", usage: "List the stations of a network", run: runStations},
+	{name: "station", usage: "Show a station", run: runStation},
+	{name: "weather", usage: "Get ASOS weather observations", run: runWeather},
+	{name: "nearest", usage: "Find the stations of networks nearest to a point", run: runNearest},
+}
+
+// env is what commands read from and write to
+type env struct {
+	client *iem.Client
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	code := run(ctx, &env{
+		client: iem.NewClient(),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}, os.Args[1:])
+
+	stop()
+	os.Exit(code)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: iem <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", c.name, c.usage)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'iem <command> -h' for the flags of a command")
+}
+
+// Runs the command named by the first argument and returns the exit code
+func run(ctx context.Context, e *env, args []string) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return exitUsage
+	}
+
+	name := args[0]
+
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		usage(e.stdout)
+		return exitOK
+	}
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+
+		err := c.run(ctx, e, args[1:])
+
+		if err == nil || errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+
+		fmt.Fprintf(e.stderr, "iem %s: %s\n", name, err)
+
+		return exitCode(ctx, err)
+	}
+
+	fmt.Fprintf(e.stderr, "iem: unknown command %q\n\n", name)
+	usage(e.stderr)
+
+	return exitUsage
+}
+
+// Maps an error to the exit code it is reported with
+func exitCode(ctx context.Context, err error) int {
+	var usageErr usageError
+	var notFoundErr iem.IEMNotFoundError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &notFoundErr):
+		return exitNotFound
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		return exitInterrupted
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return exitUnavailable
+	}
+
+	return exitError
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+	"github.com/colevoss/go-iem-sdk/iemmock"
+)
+
+func runTest(t *testing.T, client *iem.Client, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	code := run(context.Background(), &env{client: client, stdout: &stdout, stderr: &stderr}, args)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestNetworksFormats(t *testing.T) {
+	networks := iemmock.NewNetworkService()
+	networks.Return(
+		&iem.Network{Id: "NE_ASOS", Name: "Nebraska ASOS", Tz: "America/Chicago"},
+		&iem.Network{Id: "IA_ASOS", Name: "Iowa ASOS", Tz: "America/Chicago"},
+	)
+	client := iem.NewClientWithOptions(iem.WithNetworkService(networks))
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"table", "ID       NAME           TZNAME           WINDROSE_UPDATE\nNE_ASOS  Nebraska ASOS  America/Chicago  \nIA_ASOS  Iowa ASOS      America/Chicago  \n"},
+		{"csv", "id,name,tzname,windrose_update\nNE_ASOS,Nebraska ASOS,America/Chicago,\nIA_ASOS,Iowa ASOS,America/Chicago,\n"},
+	}
+
+	for _, test := range tests {
+		code, stdout, stderr := runTest(t, client, "networks", "-o", test.format)
+
+		if code != exitOK {
+			t.Fatalf("Expected exit %d. Got %d: %s", exitOK, code, stderr)
+		}
+
+		if stdout != test.expected {
+			t.Errorf("Expected %s output:\n%q\nGot:\n%q", test.format, test.expected, stdout)
+		}
+	}
+
+	_, stdout, _ := runTest(t, client, "networks", "-o", "ndjson")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 ndjson lines. Got %d", len(lines))
+	}
+
+	var network iem.Network
+
+	if err := json.Unmarshal([]byte(lines[1]), &network); err != nil || network.Id != "IA_ASOS" {
+		t.Errorf("Expected IA_ASOS ndjson line. Got %s", lines[1])
+	}
+}
+
+func TestWeather(t *testing.T) {
+	file, err := os.Open("../../data/full_weather_data.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := iem.ParseWeatherData(file, iem.NewWeatherDataQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weather := iemmock.NewWeatherService()
+	weather.Return(data...)
+	client := iem.NewClientWithOptions(iem.WithWeatherService(weather))
+
+	code, stdout, stderr := runTest(t, client,
+		"weather",
+		"-station", "LNK,OMA",
+		"-data", "tmpf",
+		"-data", "dwpf",
+		"-start", "2023-10-03",
+		"-end", "2023-10-04",
+		"-tz", "America/Chicago",
+		"-latlon",
+		"-missing", "empty",
+		"-trace", "0.0001",
+		"-report-type", "3",
+		"-format", "onlytdf",
+		"-o", "csv",
+	)
+
+	if code != exitOK {
+		t.Fatalf("Expected exit %d. Got %d: %s", exitOK, code, stderr)
+	}
+
+	weather.AssertCalled(t, 1)
+
+	values, err := weather.Queries()[0].BuildUrl()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "data=tmpf&data=dwpf&day1=3&day2=4&direct=no&elev=no&format=onlytdf&latlon=yes&missing=empty" +
+		"&month1=10&month2=10&report_type=3&station=LNK&station=OMA&trace=0.0001&tz=America%2FChicago&year1=2023&year2=2023"
+
+	if values.Encode() != expected {
+		t.Errorf("Expected query %s. Got %s", expected, values.Encode())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+
+	if lines[0] != "station,valid,lon,lat,tmpf,dwpf" {
+		t.Errorf("Expected csv header. Got %s", lines[0])
+	}
+
+	if len(lines) != len(data)+1 {
+		t.Errorf("Expected %d csv lines. Got %d", len(data)+1, len(lines))
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	notFound := iemmock.NewNetworkService()
+	notFound.ReturnError(iem.IEMNotFoundError{Detail: "Not Found", Code: 404})
+	client := iem.NewClientWithOptions(iem.WithNetworkService(notFound))
+
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{}, exitUsage},
+		{[]string{"help"}, exitOK},
+		{[]string{"bogus"}, exitUsage},
+		{[]string{"networks", "-o", "xml"}, exitUsage},
+		{[]string{"networks", "extra"}, exitUsage},
+		{[]string{"networks", "-h"}, exitOK},
+		{[]string{"networks"}, exitNotFound},
+		{[]string{"stations"}, exitUsage},
+		{[]string{"station"}, exitUsage},
+		{[]string{"weather", "-data", "tmpf"}, exitUsage},
+		{[]string{"weather", "-station", "LNK", "-start", "10/03/2023"}, exitUsage},
+		{[]string{"weather", "-station", "LNK", "-start", "2023-10-04", "-end", "2023-10-03"}, exitUsage},
+		{[]string{"weather", "-station", "LNK", "-start", "2023-10-04", "-end", "2023-10-04"}, exitUsage},
+		{[]string{"weather", "-station", "LNK", "-missing", "NA"}, exitUsage},
+		{[]string{"weather", "-station", "LNK", "-format", "xml"}, exitUsage},
+		{[]string{"nearest", "-network", "NE_ASOS", "-lat", "40.85"}, exitUsage},
+	}
+
+	for _, test := range tests {
+		code, _, _ := runTest(t, client, test.args...)
+
+		if code != test.expected {
+			t.Errorf("Expected %v to exit %d. Got %d", test.args, test.expected, code)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if code := exitCode(ctx, context.Canceled); code != exitInterrupted {
+		t.Errorf("Expected canceled exit %d. Got %d", exitInterrupted, code)
+	}
+
+	if code := exitCode(context.Background(), context.DeadlineExceeded); code != exitUnavailable {
+		t.Errorf("Expected deadline exit %d. Got %d", exitUnavailable, code)
+	}
+
+}
+
+func TestWeatherDefaultEnd(t *testing.T) {
+	weather := iemmock.NewWeatherService()
+	client := iem.NewClientWithOptions(iem.WithWeatherService(weather))
+
+	code, _, stderr := runTest(t, client, "weather", "-station", "LNK", "-start", "2023-12-31")
+
+	if code != exitOK {
+		t.Fatalf("Expected exit %d. Got %d: %s", exitOK, code, stderr)
+	}
+
+	// asos.py does not include the end day
+	weather.AssertCalledWith(t, "year2", "2024")
+	weather.AssertCalledWith(t, "month2", "1")
+	weather.AssertCalledWith(t, "day2", "1")
+}
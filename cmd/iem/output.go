@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+type outputFormat string
+
+const (
+	formatTable  outputFormat = "table"
+	formatJSON   outputFormat = "json"
+	formatNDJSON outputFormat = "ndjson"
+	formatCSV    outputFormat = "csv"
+)
+
+func (f *outputFormat) String() string {
+	return string(*f)
+}
+
+func (f *outputFormat) Set(value string) error {
+	switch format := outputFormat(value); format {
+	case formatTable, formatJSON, formatNDJSON, formatCSV:
+		*f = format
+		return nil
+	}
+
+	return fmt.Errorf("expected table, json, ndjson or csv")
+}
+
+// Writes items in a format. Table and CSV output have a column per header
+// with the values row returns for each item
+func writeRecords[T any](w io.Writer, format outputFormat, items []T, header []string, row func(item T) []string) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, items)
+	case formatNDJSON:
+		return writeNDJSON(w, items)
+	}
+
+	rows := make([][]string, 0, len(items))
+
+	for _, item := range items {
+		rows = append(rows, row(item))
+	}
+
+	if format == formatCSV {
+		return writeCSV(w, header, rows)
+	}
+
+	return writeTable(w, header, rows)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+func writeNDJSON[T any](w io.Writer, items []T) error {
+	encoder := json.NewEncoder(w)
+
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	upper := make([]string, len(header))
+
+	for i, h := range header {
+		upper[i] = strings.ToUpper(h)
+	}
+
+	fmt.Fprintln(writer, strings.Join(upper, "\t"))
+
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+
+	return writer.Flush()
+}
+
+// Writes CSV data as a table
+func csvToTable(w io.Writer, data []byte) error {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	return writeTable(w, records[0], records[1:])
+}
@@ -0,0 +1,270 @@
+package iem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type COOPVariable string
+
+const (
+	COOPHighF         COOPVariable = "max_temp_f" // High Temperature [F]
+	COOPLowF          COOPVariable = "min_temp_f" // Low Temperature [F]
+	COOPPrecipInch    COOPVariable = "precip_in"  // Precipitation [inch]
+	COOPSnowInch      COOPVariable = "snow_in"    // Snowfall [inch]
+	COOPSnowDepthInch COOPVariable = "snowd_in"   // Snow Depth [inch]
+)
+
+// COOPNetwork is the IEM network id of a state's COOP stations (IA -> IA_COOP)
+func COOPNetwork(state string) string {
+	return fmt.Sprintf("%s_COOP", strings.ToUpper(state))
+}
+
+// COOPDailyData represents a daily observation at a COOP station
+// Properties are present depending on the query used to fetch the data
+type COOPDailyData struct {
+	Station string `json:"station"` // Station recorded at (station)
+
+	Date *time.Time `json:"date"` // Date of the observation (day)
+
+	HighF float64 `json:"max_temp_f,omitempty"` // High Temperature [F] (max_temp_f)
+	LowF  float64 `json:"min_temp_f,omitempty"` // Low Temperature [F] (min_temp_f)
+
+	PrecipInch    float64 `json:"precip_in,omitempty"` // Precipitation [inch] (precip_in)
+	SnowInch      float64 `json:"snow_in,omitempty"`   // Snowfall [inch] (snow_in)
+	SnowDepthInch float64 `json:"snowd_in,omitempty"`  // Snow Depth [inch] (snowd_in)
+
+	// Variables that were observed, missing or trace amounts. Bits are the
+	// variables' indexes in coopVariables
+	state columnState
+}
+
+// Variables parsed into COOPDailyData
+var coopVariables = []COOPVariable{COOPHighF, COOPLowF, COOPPrecipInch, COOPSnowInch, COOPSnowDepthInch}
+
+func (d *COOPDailyData) field(variable COOPVariable) *float64 {
+	switch variable {
+	case COOPHighF:
+		return &d.HighF
+	case COOPLowF:
+		return &d.LowF
+	case COOPPrecipInch:
+		return &d.PrecipInch
+	case COOPSnowInch:
+		return &d.SnowInch
+	case COOPSnowDepthInch:
+		return &d.SnowDepthInch
+	}
+
+	return nil
+}
+
+// IsMissing reports whether a variable is missing. Parsed data knows which of
+// its variables were missing or not requested. Otherwise, as for data created
+// by hand, zero temperatures are missing while precipitation and snow are
+// routinely 0
+func (d *COOPDailyData) IsMissing(variable COOPVariable) bool {
+	if i, ok := variableIndex(coopVariables, variable); ok {
+		if missing, known := d.state.isMissing(i); known {
+			return missing
+		}
+	}
+
+	field := d.field(variable)
+
+	if field == nil {
+		return true
+	}
+
+	return *field == 0 && (variable == COOPHighF || variable == COOPLowF)
+}
+
+// IsTrace reports whether a variable is a trace amount. Traces have a value of 0
+func (d *COOPDailyData) IsTrace(variable COOPVariable) bool {
+	i, ok := variableIndex(coopVariables, variable)
+
+	return ok && d.state.trace.has(i)
+}
+
+// COOPQueryBuilder represents the url query sent to the IEM API
+// when requesting COOP daily data for stations
+type COOPQueryBuilder struct {
+	// Network the stations belong to (IA_COOP)
+	network string
+
+	// Stations to get daily data from (IATAME)
+	stations []string
+
+	// List of variables requested from IEM API
+	data []COOPVariable
+
+	// Start date to query for
+	start time.Time
+
+	// End date to query for
+	end time.Time
+}
+
+// Creates a new COOPQueryBuilder with defaults set to optional fields
+func NewCOOPQuery() *COOPQueryBuilder {
+	return &COOPQueryBuilder{
+		start: time.Now(),
+		end:   time.Now(),
+	}
+}
+
+func (b *COOPQueryBuilder) isMissingOrTrace(value string) bool {
+	return value == string(MissingM) || b.isTrace(value)
+}
+
+// daily.py reports trace amounts as 0.0001
+func (b *COOPQueryBuilder) isTrace(value string) bool {
+	return value == "0.0001" || value == string(TraceT)
+}
+
+// Sets query builder network (IA_COOP)
+func (b *COOPQueryBuilder) Network(network string) *COOPQueryBuilder {
+	b.network = network
+	return b
+}
+
+// Appends stations to builder.station
+func (b *COOPQueryBuilder) Stations(stations ...string) *COOPQueryBuilder {
+	b.stations = append(b.stations, stations...)
+	return b
+}
+
+// Appends variables to builder.data
+func (b *COOPQueryBuilder) Data(data ...COOPVariable) *COOPQueryBuilder {
+	b.data = append(b.data, data...)
+	return b
+}
+
+// Sets query builder start date (defaults to today)
+func (b *COOPQueryBuilder) Start(t time.Time) *COOPQueryBuilder {
+	b.start = t
+	return b
+}
+
+// Sets query builder end date (defaults to today)
+func (b *COOPQueryBuilder) End(t time.Time) *COOPQueryBuilder {
+	b.end = t
+	return b
+}
+
+// Creates url.Values with validated data from query builder
+func (b *COOPQueryBuilder) BuildUrl() (url.Values, error) {
+	v := url.Values{}
+
+	if b.network == "" {
+		return nil, builderRequiredError("COOPQueryBuilder", "network")
+	}
+
+	v.Add("network", b.network)
+
+	if b.stations == nil {
+		return nil, builderRequiredError("COOPQueryBuilder", "stations")
+	}
+
+	for _, s := range b.stations {
+		v.Add("stations", s)
+	}
+
+	if b.data == nil {
+		return nil, builderRequiredError("COOPQueryBuilder", "data")
+	}
+
+	for _, d := range b.data {
+		v.Add("var", string(d))
+	}
+
+	v.Add("year1", strconv.Itoa(b.start.Year()))
+	v.Add("month1", strconv.Itoa(int(b.start.Month())))
+	v.Add("day1", strconv.Itoa(b.start.Day()))
+
+	v.Add("year2", strconv.Itoa(b.end.Year()))
+	v.Add("month2", strconv.Itoa(int(b.end.Month())))
+	v.Add("day2", strconv.Itoa(b.end.Day()))
+
+	v.Add("format", "csv")
+	v.Add("na", string(MissingM))
+
+	return v, nil
+}
+
+type COOPService interface {
+	Get(ctx context.Context, query *COOPQueryBuilder) ([]*COOPDailyData, error)
+
+	// Gets the COOP stations of a state (IA)
+	GetStations(ctx context.Context, state string) ([]*Station, error)
+}
+
+type IEMCOOPService struct {
+	client *Client
+}
+
+func (s *IEMCOOPService) Get(ctx context.Context, query *COOPQueryBuilder) ([]*COOPDailyData, error) {
+	v, err := query.BuildUrl()
+
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("/cgi-bin/request/daily.py?%s", v.Encode())
+
+	var data []*COOPDailyData
+
+	err = s.client.get(ctx, url, func(body io.Reader) (err error) {
+		data, err = ParseCOOPDailyData(body, query)
+		return err
+	})
+
+	return data, err
+}
+
+func (s *IEMCOOPService) GetStations(ctx context.Context, state string) ([]*Station, error) {
+	return s.client.Stations().GetStations(ctx, COOPNetwork(state))
+}
+
+// Parse COOP daily data from a io.Reader that reads CSV data based on a COOPQueryBuilder
+func ParseCOOPDailyData(reader io.Reader, query *COOPQueryBuilder) ([]*COOPDailyData, error) {
+	data := []*COOPDailyData{}
+
+	err := readCsvRecords(reader, func(w *weatherDataIndecies, record *[]string) error {
+		d := &COOPDailyData{}
+
+		err := errors.Join(
+			w.setString("station", record, &d.Station, query),
+			w.setTimeLayout("day", "2006-01-02", record, &d.Date, query),
+			w.setFloat("max_temp_f", record, &d.HighF, query),
+			w.setFloat("min_temp_f", record, &d.LowF, query),
+			w.setFloat("precip_in", record, &d.PrecipInch, query),
+			w.setFloat("snow_in", record, &d.SnowInch, query),
+			w.setFloat("snowd_in", record, &d.SnowDepthInch, query),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		for i, v := range coopVariables {
+			d.state.parse(i, string(v), w, record, query)
+		}
+
+		data = append(data, d)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,63 @@
+package iem
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseCOOPDailyData(t *testing.T) {
+	file, err := os.Open("./data/coop_daily.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := ParseCOOPDailyData(file, NewCOOPQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 4 {
+		t.Fatalf("expected 4 days, got %d", len(data))
+	}
+
+	d := data[0]
+
+	if d.Station != "IATAME" || !d.Date.Equal(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected station and date %s %s", d.Station, d.Date)
+	}
+
+	if d.HighF != 31 || d.LowF != 22 || d.PrecipInch != 0.42 || d.SnowInch != 3.1 || d.SnowDepthInch != 4 {
+		t.Errorf("unexpected values %+v", d)
+	}
+
+	// daily.py reports traces as 0.0001
+	trace := data[1]
+
+	if !trace.IsTrace(COOPPrecipInch) || !trace.IsTrace(COOPSnowInch) || trace.IsMissing(COOPPrecipInch) || trace.PrecipInch != 0 {
+		t.Errorf("unexpected trace values %+v", trace)
+	}
+
+	if trace.IsTrace(COOPSnowDepthInch) || trace.IsMissing(COOPSnowDepthInch) {
+		t.Errorf("unexpected snow depth %+v", trace)
+	}
+
+	missing := data[2]
+
+	if !missing.IsMissing(COOPHighF) || !missing.IsMissing(COOPSnowDepthInch) || missing.IsTrace(COOPHighF) || missing.LowF != 15 {
+		t.Errorf("unexpected values with missing high %+v", missing)
+	}
+
+	// Observed zeros are not missing
+	if missing.IsMissing(COOPPrecipInch) || missing.IsMissing(COOPSnowInch) || missing.IsTrace(COOPPrecipInch) {
+		t.Errorf("unexpected observed zeros %+v", missing)
+	}
+
+	if data[3].Station != "IA2203" {
+		t.Errorf("unexpected station %s", data[3].Station)
+	}
+}
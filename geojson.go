@@ -0,0 +1,257 @@
+package iem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// PointGeometry is a GeoJSON point
+type PointGeometry struct {
+	Type        string   `json:"type"`
+	Coordinates Position `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature with a point geometry. Geometry is nil when
+// the location is not known
+type Feature struct {
+	Type       string                 `json:"type"`
+	Id         string                 `json:"id,omitempty"`
+	Geometry   *PointGeometry         `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	BBox     []float64  `json:"bbox,omitempty"` // [west, south, east, north]
+	Features []*Feature `json:"features"`
+}
+
+// GeoJSONOptions configures GeoJSON output
+type GeoJSONOptions struct {
+	// JSON names of the properties to include (tmpf, name, etc). All properties
+	// are included when empty
+	Properties []string
+
+	// Include the bounding box of the features in the collection
+	BBox bool
+}
+
+func pointGeometry(lon, lat float64) *PointGeometry {
+	return &PointGeometry{Type: "Point", Coordinates: Position{lon, lat}}
+}
+
+// Properties of a value are its JSON encoding, filtered to the selected properties
+func geoJSONProperties(value interface{}, selected []string) (map[string]interface{}, error) {
+	body, err := json.Marshal(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]interface{}{}
+
+	if err := json.Unmarshal(body, &properties); err != nil {
+		return nil, err
+	}
+
+	return selectProperties(properties, selected), nil
+}
+
+func selectProperties(properties map[string]interface{}, selected []string) map[string]interface{} {
+	if len(selected) == 0 {
+		return properties
+	}
+
+	filtered := make(map[string]interface{}, len(selected))
+
+	for _, name := range selected {
+		if v, ok := properties[name]; ok {
+			filtered[name] = v
+		}
+	}
+
+	return filtered
+}
+
+// Trace amounts in weather GeoJSON properties, like asos.py's default trace encoding
+const geoJSONTrace = "T"
+
+// Properties of an observation for each of columns. Missing values are null
+// and trace amounts are "T"
+func weatherGeoJSONProperties(d *IEMWeatherData, columns []WeatherDataData) map[string]interface{} {
+	properties := map[string]interface{}{"station": d.Station, "time": d.Time}
+
+	if d.Elevation != "" {
+		properties["elevation"] = d.Elevation
+	}
+
+	for _, c := range columns {
+		switch {
+		case d.IsTrace(c):
+			properties[string(c)] = geoJSONTrace
+		case d.IsMissing(c):
+			properties[string(c)] = nil
+		case c == PeakWindTime:
+			properties[string(c)] = d.PeakWindTime
+		default:
+			if text, ok := d.Text(c); ok {
+				properties[string(c)] = text
+			} else {
+				properties[string(c)], _ = d.Float(c)
+			}
+		}
+	}
+
+	return properties
+}
+
+func featureCollection(features []*Feature, options GeoJSONOptions) *FeatureCollection {
+	collection := &FeatureCollection{Type: "FeatureCollection", Features: features}
+
+	if !options.BBox {
+		return collection
+	}
+
+	bbox := []float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	found := false
+
+	for _, f := range features {
+		if f.Geometry == nil {
+			continue
+		}
+
+		lon, lat := f.Geometry.Coordinates[0], f.Geometry.Coordinates[1]
+		bbox[0], bbox[1] = math.Min(bbox[0], lon), math.Min(bbox[1], lat)
+		bbox[2], bbox[3] = math.Max(bbox[2], lon), math.Max(bbox[3], lat)
+		found = true
+	}
+
+	if found {
+		collection.BBox = bbox
+	}
+
+	return collection
+}
+
+// StationsGeoJSON creates a feature collection with a point feature for each
+// station. Properties are the station's JSON fields
+func StationsGeoJSON(stations []*Station, options GeoJSONOptions) (*FeatureCollection, error) {
+	features := make([]*Feature, 0, len(stations))
+
+	for _, s := range stations {
+		properties, err := geoJSONProperties(s, options.Properties)
+
+		if err != nil {
+			return nil, err
+		}
+
+		features = append(features, &Feature{
+			Type:       "Feature",
+			Id:         s.Id,
+			Geometry:   pointGeometry(s.Longitude, s.Latitude),
+			Properties: properties,
+		})
+	}
+
+	return featureCollection(features, options), nil
+}
+
+// WeatherDataGeoJSON creates a feature collection with a point feature for each
+// observation. Observations need lon and lat (LatLon(true)), features of
+// observations without them have no geometry.
+//
+// Properties are the columns that are not missing in at least one of the
+// observations, so every feature has the same properties. Missing values are
+// null and trace amounts are "T"
+func WeatherDataGeoJSON(data []*IEMWeatherData, options GeoJSONOptions) (*FeatureCollection, error) {
+	columns := []WeatherDataData{}
+
+	for _, c := range weatherStateColumns {
+		for _, d := range data {
+			if !d.IsMissing(c) {
+				columns = append(columns, c)
+				break
+			}
+		}
+	}
+
+	features := make([]*Feature, 0, len(data))
+
+	for _, d := range data {
+		properties := selectProperties(weatherGeoJSONProperties(d, columns), options.Properties)
+		feature := &Feature{Type: "Feature", Properties: properties}
+
+		if !d.IsMissing(Longitude) && !d.IsMissing(Latitude) {
+			feature.Geometry = pointGeometry(d.Lon, d.Lat)
+		}
+
+		features = append(features, feature)
+	}
+
+	return featureCollection(features, options), nil
+}
+
+// Station properties of IEM's GeoJSON station endpoints that differ from the JSON API
+type iemStationGeoJSONProperties struct {
+	Station
+	Sid          string `json:"sid"`
+	Sname        string `json:"sname"`
+	ArchiveBegin string `json:"archive_begin"`
+}
+
+// ParseStationsGeoJSON parses a feature collection of stations from IEM's GeoJSON
+// station endpoints (/geojson/network/IA_ASOS.geojson) or StationsGeoJSON
+func ParseStationsGeoJSON(reader io.Reader) ([]*Station, error) {
+	var collection geoJSONFeatureCollection
+
+	if err := json.NewDecoder(reader).Decode(&collection); err != nil {
+		return nil, err
+	}
+
+	stations := make([]*Station, 0, len(collection.Features))
+
+	for _, f := range collection.Features {
+		var properties iemStationGeoJSONProperties
+
+		if err := json.Unmarshal(f.Properties, &properties); err != nil {
+			return nil, err
+		}
+
+		station := properties.Station
+
+		if properties.Sid != "" {
+			station.Id = properties.Sid
+		}
+
+		if properties.Sname != "" {
+			station.Name = properties.Sname
+		}
+
+		if properties.ArchiveBegin != "" {
+			begins, err := parseIEMTime(properties.ArchiveBegin)
+
+			if err != nil {
+				return nil, fmt.Errorf("error parsing archive_begin [%w]", err)
+			}
+
+			station.ArchiveBegins = begins
+		}
+
+		if f.Geometry != nil && f.Geometry.Type == "Point" {
+			var point Position
+
+			if err := json.Unmarshal(f.Geometry.Coordinates, &point); err != nil {
+				return nil, err
+			}
+
+			station.Longitude, station.Latitude = point[0], point[1]
+		}
+
+		stations = append(stations, &station)
+	}
+
+	return stations, nil
+}
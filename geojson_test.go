@@ -0,0 +1,175 @@
+package iem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const iemStationsGeoJSON = `{"type": "FeatureCollection", "features": [
+	{"type": "Feature", "id": "DSM", "properties": {"sid": "DSM", "sname": "DES MOINES INTL", "network": "IA_ASOS", "state": "IA", "elevation": 291.0, "archive_begin": "1933-01-01", "climate_site": "IA2203", "tzname": "America/Chicago", "online": true},
+	 "geometry": {"type": "Point", "coordinates": [-93.6534, 41.5341]}}
+]}`
+
+func TestParseStationsGeoJSON(t *testing.T) {
+	stations, err := ParseStationsGeoJSON(strings.NewReader(iemStationsGeoJSON))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stations) != 1 {
+		t.Fatalf("expected 1 station, got %d", len(stations))
+	}
+
+	s := stations[0]
+
+	if s.Id != "DSM" || s.Name != "DES MOINES INTL" || s.ClimateSite != "IA2203" || s.Longitude != -93.6534 || s.Latitude != 41.5341 {
+		t.Errorf("unexpected station %+v", s)
+	}
+
+	if !s.ArchiveBegins.Equal(time.Date(1933, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected archive begin %v", s.ArchiveBegins)
+	}
+
+	// Encoded stations parse back to the same station
+	collection, err := StationsGeoJSON(stations, GeoJSONOptions{BBox: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(collection)
+	parsed, err := ParseStationsGeoJSON(bytes.NewReader(body))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *parsed[0] != *s {
+		t.Errorf("expected %+v, got %+v", s, parsed[0])
+	}
+
+	if len(collection.BBox) != 4 || collection.BBox[0] != -93.6534 {
+		t.Errorf("unexpected bbox %v", collection.BBox)
+	}
+}
+
+func TestGetStationsGeoJSON(t *testing.T) {
+	var path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(iemStationsGeoJSON))
+	}))
+
+	defer server.Close()
+
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+	getter, ok := client.Stations().(StationGeoJSONGetter)
+
+	if !ok {
+		t.Fatal("expected station service to implement StationGeoJSONGetter")
+	}
+
+	stations, err := getter.GetStationsGeoJSON(context.Background(), "IA_ASOS")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/geojson/network/IA_ASOS.geojson" {
+		t.Errorf("unexpected path %s", path)
+	}
+
+	if len(stations) != 1 || stations[0].Id != "DSM" || stations[0].Network != "IA_ASOS" {
+		t.Errorf("unexpected stations %+v", stations)
+	}
+}
+
+func TestWeatherDataGeoJSON(t *testing.T) {
+	now := time.Now()
+
+	data := []*IEMWeatherData{
+		{Station: "LNK", Time: &now, Lon: -96.7633, Lat: 40.8312, TemperatureF: 79},
+		{Station: "DSM", Time: &now, Lon: -93.6534, Lat: 41.5341, TemperatureF: 75},
+		{Station: "XXX", Time: &now, TemperatureF: 70},
+	}
+
+	collection, err := WeatherDataGeoJSON(data, GeoJSONOptions{Properties: []string{"station", "tmpf"}, BBox: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collection.Features) != 3 || collection.Features[2].Geometry != nil {
+		t.Fatalf("unexpected features %+v", collection.Features)
+	}
+
+	properties := collection.Features[0].Properties
+
+	if len(properties) != 2 || properties["tmpf"] != 79.0 {
+		t.Errorf("unexpected properties %v", properties)
+	}
+
+	expected := []float64{-96.7633, 40.8312, -93.6534, 41.5341}
+
+	for i, v := range expected {
+		if collection.BBox[i] != v {
+			t.Fatalf("expected bbox %v, got %v", expected, collection.BBox)
+		}
+	}
+}
+
+func TestWeatherDataGeoJSONMissingAndTrace(t *testing.T) {
+	query := NewWeatherDataQuery().Data(TempF, PrecipInch, WindGustKnots, Visibility).LatLon(true)
+	body := "station,valid,lon,lat,tmpf,p01i,gust,vsby\n" +
+		"LNK,2023-01-04 06:54,-96.7633,40.8312,0.00,T,M,M\n" +
+		"XXX,2023-01-04 06:54,M,M,-2.00,0.00,25.00,M\n"
+
+	data, err := ParseWeatherData(strings.NewReader(body), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := WeatherDataGeoJSON(data, GeoJSONOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lnk, xxx := collection.Features[0], collection.Features[1]
+
+	if lnk.Geometry == nil || xxx.Geometry != nil {
+		t.Errorf("expected a geometry only for LNK %+v %+v", lnk.Geometry, xxx.Geometry)
+	}
+
+	properties := lnk.Properties
+
+	if v, ok := properties["tmpf"]; !ok || v != 0.0 {
+		t.Errorf("expected an observed 0F, got %v", properties)
+	}
+
+	if properties["p01i"] != "T" || properties["gust"] != nil {
+		t.Errorf("expected trace precip and a null gust, got %v", properties)
+	}
+
+	if _, ok := properties["gust"]; !ok {
+		t.Errorf("expected a null gust property, got %v", properties)
+	}
+
+	// Columns missing from every observation are left out
+	if _, ok := properties["vsby"]; ok {
+		t.Errorf("expected no vsby property, got %v", properties)
+	}
+
+	if xxx.Properties["lon"] != nil || xxx.Properties["p01i"] != 0.0 {
+		t.Errorf("unexpected XXX properties %v", xxx.Properties)
+	}
+}
@@ -0,0 +1,149 @@
+package iem
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Position is a GeoJSON position as [longitude, latitude]
+type Position [2]float64
+
+// Polygon is a GeoJSON polygon. The first ring is the exterior and any
+// following rings are holes
+type Polygon [][]Position
+
+// MultiPolygon is a collection of polygons
+type MultiPolygon []Polygon
+
+// Contains reports whether a lon/lat point is inside the polygon and outside all of its holes
+func (p Polygon) Contains(lon, lat float64) bool {
+	if len(p) == 0 || !ringContains(p[0], lon, lat) {
+		return false
+	}
+
+	for _, hole := range p[1:] {
+		if ringContains(hole, lon, lat) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Contains reports whether a lon/lat point is inside any of the polygons
+func (m MultiPolygon) Contains(lon, lat float64) bool {
+	for _, p := range m {
+		if p.Contains(lon, lat) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContainsStation reports whether the station's coordinates are inside any of the polygons
+func (m MultiPolygon) ContainsStation(station *Station) bool {
+	return m.Contains(station.Longitude, station.Latitude)
+}
+
+// Ray casting point in polygon test
+func ringContains(ring []Position, lon, lat float64) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string           `json:"type"`
+	Properties json.RawMessage  `json:"properties"`
+	Geometry   *geoJSONGeometry `json:"geometry"`
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []*geoJSONFeature `json:"features"`
+}
+
+// Decodes Polygon and MultiPolygon geometries. Any other geometry type is an error
+func (g *geoJSONGeometry) multiPolygon() (MultiPolygon, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	switch g.Type {
+	case "Polygon":
+		var polygon Polygon
+
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return nil, err
+		}
+
+		return MultiPolygon{polygon}, nil
+
+	case "MultiPolygon":
+		var multiPolygon MultiPolygon
+
+		if err := json.Unmarshal(g.Coordinates, &multiPolygon); err != nil {
+			return nil, err
+		}
+
+		return multiPolygon, nil
+	}
+
+	return nil, fmt.Errorf("unsupported geometry type %s", g.Type)
+}
+
+const earthRadiusKm = 6371.0088
+
+// DistanceKm is the great circle distance between two lat/lon points [km]
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// DistanceKm is the great circle distance to another station [km]
+func (s *Station) DistanceKm(other *Station) float64 {
+	return DistanceKm(s.Latitude, s.Longitude, other.Latitude, other.Longitude)
+}
+
+// ClosestStation finds the station closest to a lat/lon point and its distance [km].
+// Nil when stations is empty
+func ClosestStation(stations []*Station, lat, lon float64) (*Station, float64) {
+	var closest *Station
+	closestDistance := math.Inf(1)
+
+	for _, station := range stations {
+		distance := DistanceKm(lat, lon, station.Latitude, station.Longitude)
+
+		if distance < closestDistance {
+			closest = station
+			closestDistance = distance
+		}
+	}
+
+	return closest, closestDistance
+}
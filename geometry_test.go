@@ -0,0 +1,88 @@
+package iem
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		{"same point", 40.85, -96.76, 40.85, -96.76, 0},
+		{"one degree of latitude", 0, 0, 1, 0, 111.195},
+		{"one degree of longitude at the equator", 0, 0, 0, 1, 111.195},
+		{"across the antimeridian", 0, 179.5, 0, -179.5, 111.195},
+		{"JFK to LAX", 40.6413, -73.7781, 33.9416, -118.4085, 3974.3},
+	}
+
+	for _, test := range tests {
+		distance := DistanceKm(test.lat1, test.lon1, test.lat2, test.lon2)
+
+		if math.Abs(distance-test.expected) > 0.5 {
+			t.Errorf("%s: distance = %f, want %f", test.name, distance, test.expected)
+		}
+
+		if reverse := DistanceKm(test.lat2, test.lon2, test.lat1, test.lon1); math.Abs(reverse-distance) > 1e-9 {
+			t.Errorf("%s: expected the same distance in reverse, got %f and %f", test.name, distance, reverse)
+		}
+	}
+}
+
+func TestClosestStation(t *testing.T) {
+	body, err := os.ReadFile("./data/nexrad_stations.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/1/network/NEXRAD.json" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(body)
+	}))
+
+	defer server.Close()
+
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+	sites, err := client.Radar().GetSites(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lnk := &Station{Id: "LNK", Latitude: 40.8312, Longitude: -96.7633}
+	site, distance := ClosestStation(sites, lnk.Latitude, lnk.Longitude)
+
+	if site == nil || site.Id != "OAX" || math.Abs(distance-63.7) > 0.5 {
+		t.Errorf("expected OAX about 63.7 km away, got %v %f", site, distance)
+	}
+
+	if d := lnk.DistanceKm(site); d != distance {
+		t.Errorf("expected station distance %f, got %f", distance, d)
+	}
+
+	// Sioux City is about 120 km from Omaha and 135 km from Sioux Falls
+	if site, distance := ClosestStation(sites, 42.4026, -96.3844); site.Id != "OAX" || math.Abs(distance-120.3) > 0.5 {
+		t.Errorf("expected OAX for Sioux City, got %s %f", site.Id, distance)
+	}
+
+	closest, closestDistance, err := client.Radar().GetClosestSite(context.Background(), lnk)
+
+	if err != nil || closest.Id != "OAX" || closestDistance != distance {
+		t.Errorf("expected OAX from GetClosestSite, got %v %f %v", closest, closestDistance, err)
+	}
+
+	if site, distance := ClosestStation(nil, 0, 0); site != nil || !math.IsInf(distance, 1) {
+		t.Errorf("expected no station, got %v %f", site, distance)
+	}
+}
@@ -1,20 +1,37 @@
 package iem
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type Client struct {
 	client  *http.Client
 	baseUrl string
 
+	cache       Cache
+	cachePolicy CachePolicy
+
 	networkService NetworkService
 	weatherService WeatherService
 	stationService StationService
+	vtecService    VTECService
+	raobService    RAOBService
+	mosService     MOSService
+	tafService     TAFService
+	isusmService   ISUSMService
+	coopService    COOPService
+	climateService ClimateService
+	iemreService   IEMREService
+	radarService   RadarService
+	outlookService OutlookService
+	rwisService    RWISService
 }
 
 type ClientOption func(*Client)
@@ -31,6 +48,101 @@ func WithWeatherService(service WeatherService) ClientOption {
 	}
 }
 
+func WithStationService(service StationService) ClientOption {
+	return func(client *Client) {
+		client.stationService = service
+	}
+}
+
+func WithVTECService(service VTECService) ClientOption {
+	return func(client *Client) {
+		client.vtecService = service
+	}
+}
+
+func WithRAOBService(service RAOBService) ClientOption {
+	return func(client *Client) {
+		client.raobService = service
+	}
+}
+
+func WithMOSService(service MOSService) ClientOption {
+	return func(client *Client) {
+		client.mosService = service
+	}
+}
+
+func WithTAFService(service TAFService) ClientOption {
+	return func(client *Client) {
+		client.tafService = service
+	}
+}
+
+func WithISUSMService(service ISUSMService) ClientOption {
+	return func(client *Client) {
+		client.isusmService = service
+	}
+}
+
+func WithCOOPService(service COOPService) ClientOption {
+	return func(client *Client) {
+		client.coopService = service
+	}
+}
+
+func WithClimateService(service ClimateService) ClientOption {
+	return func(client *Client) {
+		client.climateService = service
+	}
+}
+
+func WithIEMREService(service IEMREService) ClientOption {
+	return func(client *Client) {
+		client.iemreService = service
+	}
+}
+
+func WithRadarService(service RadarService) ClientOption {
+	return func(client *Client) {
+		client.radarService = service
+	}
+}
+
+func WithOutlookService(service OutlookService) ClientOption {
+	return func(client *Client) {
+		client.outlookService = service
+	}
+}
+
+func WithRWISService(service RWISService) ClientOption {
+	return func(client *Client) {
+		client.rwisService = service
+	}
+}
+
+// Caches responses by their normalized request url for the TTLs of policy
+func WithCache(cache Cache, policy CachePolicy) ClientOption {
+	return func(client *Client) {
+		client.cache = cache
+		client.cachePolicy = policy
+	}
+}
+
+// Sends requests with an http.Client, such as one with a recording or replaying
+// http.RoundTripper
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(client *Client) {
+		client.client = httpClient
+	}
+}
+
+// Sends requests to a base url other than IEM's, such as a test server
+func WithBaseUrl(baseUrl string) ClientOption {
+	return func(client *Client) {
+		client.baseUrl = strings.TrimSuffix(baseUrl, "/")
+	}
+}
+
 const iemUrl = "https://mesonet.agron.iastate.edu"
 
 func NewClient() *Client {
@@ -42,6 +154,17 @@ func NewClient() *Client {
 	client.networkService = &IEMNetworkService{client}
 	client.weatherService = &IEMWeatherService{client}
 	client.stationService = &IEMStationService{client}
+	client.vtecService = &IEMVTECService{client}
+	client.raobService = &IEMRAOBService{client}
+	client.mosService = &IEMMOSService{client}
+	client.tafService = &IEMTAFService{client}
+	client.isusmService = &IEMISUSMService{client}
+	client.coopService = &IEMCOOPService{client}
+	client.climateService = &IEMClimateService{client}
+	client.iemreService = &IEMIEMREService{client}
+	client.radarService = &IEMRadarService{client}
+	client.outlookService = &IEMOutlookService{client}
+	client.rwisService = &IEMRWISService{client}
 
 	return client
 }
@@ -56,8 +179,76 @@ func NewClientWithOptions(opts ...ClientOption) *Client {
 	return client
 }
 
-func (c *Client) get(ctx context.Context, url string) (io.ReadCloser, error) {
+// Requests a url of the IEM API and parses its body. Cached bodies are only
+// stored once parse succeeds, so error messages served with a 200 are not cached
+func (c *Client) get(ctx context.Context, url string, parse func(body io.Reader) error) error {
+	resp, err := c.fetch(ctx, url)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.body.Close()
+
+	// TODO: Handle HTTP Errors
+
+	if resp.cacheKey == "" {
+		return parse(resp.body)
+	}
+
+	body, err := io.ReadAll(resp.body)
+
+	if err != nil {
+		return err
+	}
+
+	if err := parse(bytes.NewReader(body)); err != nil {
+		return err
+	}
+
+	c.cache.Set(resp.cacheKey, body, resp.cacheTTL)
+
+	return nil
+}
+
+// Requests a url of the IEM API to be read as a stream. Streamed bodies are
+// served from the cache but not stored since they are parsed after returning
+func (c *Client) stream(ctx context.Context, url string) (io.ReadCloser, error) {
+	resp, err := c.fetch(ctx, url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.body, nil
+}
+
+// A response of the IEM API
+type response struct {
+	status int
+	body   io.ReadCloser
+
+	// Key and TTL to store the body with once it is parsed. Empty when the
+	// response was served from the cache or can not be cached
+	cacheKey string
+	cacheTTL time.Duration
+}
+
+// Requests a url of the IEM API. Responses are served from the client's cache
+// when the cache policy allows. Successful responses that can be cached are
+// returned with the key to store them with
+func (c *Client) fetch(ctx context.Context, url string) (*response, error) {
 	requestUrl := fmt.Sprintf("%s%s", c.baseUrl, url)
+	key := NormalizeURL(requestUrl)
+
+	ttl, cacheable := c.cachePolicy.TTL(requestUrl, time.Now())
+	cacheable = cacheable && c.cache != nil
+
+	if cacheable {
+		if body, ok := c.cache.Get(key); ok {
+			return &response{status: http.StatusOK, body: io.NopCloser(bytes.NewReader(body))}, nil
+		}
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
 
@@ -71,9 +262,11 @@ func (c *Client) get(ctx context.Context, url string) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	// TODO: Handle HTTP Errors
+	if !cacheable || resp.StatusCode != http.StatusOK {
+		return &response{status: resp.StatusCode, body: resp.Body}, nil
+	}
 
-	return resp.Body, nil
+	return &response{status: resp.StatusCode, body: resp.Body, cacheKey: key, cacheTTL: ttl}, nil
 }
 
 type IEMNotFoundError struct {
@@ -86,29 +279,21 @@ func (err IEMNotFoundError) Error() string {
 }
 
 func (c *Client) getJson(ctx context.Context, url string, result interface{}) error {
-	requestUrl := fmt.Sprintf("%s%s", c.baseUrl, url)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
-
-	if err != nil {
-		return err
-	}
-
-	res, err := c.client.Do(req)
+	resp, err := c.fetch(ctx, url)
 
 	if err != nil {
 		return err
 	}
 
-	defer res.Body.Close()
+	defer resp.body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	body, err := io.ReadAll(resp.body)
 
 	if err != nil {
 		return err
 	}
 
-	if res.StatusCode == 404 {
+	if resp.status == 404 {
 		var notFoundError IEMNotFoundError
 
 		if err = json.Unmarshal(body, &notFoundError); err != nil {
@@ -124,6 +309,10 @@ func (c *Client) getJson(ctx context.Context, url string, result interface{}) er
 		return err
 	}
 
+	if resp.cacheKey != "" {
+		c.cache.Set(resp.cacheKey, body, resp.cacheTTL)
+	}
+
 	return nil
 }
 
@@ -138,3 +327,47 @@ func (c *Client) Weather() WeatherService {
 func (c *Client) Stations() StationService {
 	return c.stationService
 }
+
+func (c *Client) VTEC() VTECService {
+	return c.vtecService
+}
+
+func (c *Client) RAOB() RAOBService {
+	return c.raobService
+}
+
+func (c *Client) MOS() MOSService {
+	return c.mosService
+}
+
+func (c *Client) TAF() TAFService {
+	return c.tafService
+}
+
+func (c *Client) ISUSM() ISUSMService {
+	return c.isusmService
+}
+
+func (c *Client) COOP() COOPService {
+	return c.coopService
+}
+
+func (c *Client) Climate() ClimateService {
+	return c.climateService
+}
+
+func (c *Client) IEMRE() IEMREService {
+	return c.iemreService
+}
+
+func (c *Client) Radar() RadarService {
+	return c.radarService
+}
+
+func (c *Client) Outlooks() OutlookService {
+	return c.outlookService
+}
+
+func (c *Client) RWIS() RWISService {
+	return c.rwisService
+}
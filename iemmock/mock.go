@@ -0,0 +1,77 @@
+// Package iemmock provides configurable mocks of the SDK's NetworkService,
+// StationService and WeatherService. Mocks return scripted responses, record
+// their calls and can simulate errors and delays.
+//
+//	weather := iemmock.NewWeatherService()
+//	weather.Return(data...)
+//
+//	client := iem.NewClientWithOptions(iem.WithWeatherService(weather))
+//	// ...
+//	weather.AssertCalledWithStations(t, "LNK")
+package iemmock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Error and delay behavior shared by the mocks
+type base struct {
+	mu    sync.Mutex
+	err   error
+	next  []error
+	delay time.Duration
+}
+
+// ReturnError makes every call fail with err until it is set to nil
+func (b *base) ReturnError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.err = err
+}
+
+// FailNext makes the next calls fail with errs, one call per error
+func (b *base) FailNext(errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.next = append(b.next, errs...)
+}
+
+// SetDelay delays every call. Calls return the context's error when it is
+// done before the delay passes
+func (b *base) SetDelay(delay time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.delay = delay
+}
+
+// Waits out the delay and returns the error a call fails with
+func (b *base) begin(ctx context.Context) error {
+	b.mu.Lock()
+	delay := b.delay
+	err := b.err
+
+	if len(b.next) > 0 {
+		err = b.next[0]
+		b.next = b.next[1:]
+	}
+
+	b.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return err
+}
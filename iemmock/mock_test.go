@@ -0,0 +1,192 @@
+package iemmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+// Records assertion failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func TestClientWithMocks(t *testing.T) {
+	networks := NewNetworkService()
+	stations := NewStationService()
+	weather := NewWeatherService()
+
+	client := iem.NewClientWithOptions(
+		iem.WithNetworkService(networks),
+		iem.WithStationService(stations),
+		iem.WithWeatherService(weather),
+	)
+
+	ctx := context.Background()
+	valid := time.Date(2023, 10, 4, 0, 54, 0, 0, time.UTC)
+
+	networks.Return(&iem.Network{Id: "NE_ASOS"})
+	stations.AddStations(&iem.Station{Id: "LNK", Network: "NE_ASOS"})
+	weather.Return(&iem.IEMWeatherData{Station: "LNK", Time: &valid, TemperatureF: 79})
+
+	if n, err := client.Networks().GetNetworks(ctx); err != nil || len(n) != 1 {
+		t.Errorf("Expected 1 network. Got %v %v", n, err)
+	}
+
+	if s, err := client.Stations().GetStation(ctx, "LNK"); err != nil || s.Id != "LNK" {
+		t.Errorf("Expected LNK. Got %v %v", s, err)
+	}
+
+	var notFound iem.IEMNotFoundError
+
+	if _, err := client.Stations().GetStations(ctx, "IA_ASOS"); !errors.As(err, &notFound) {
+		t.Errorf("Expected IEMNotFoundError. Got %v", err)
+	}
+
+	query := iem.NewWeatherDataQuery().Stations("LNK").Data(iem.TempF)
+
+	data, err := client.Weather().Get(ctx, query)
+
+	if err != nil || len(data) != 1 || data[0].TemperatureF != 79 {
+		t.Errorf("Expected scripted observation. Got %v %v", data, err)
+	}
+
+	reader, err := client.Weather().(iem.WeatherStreamer).Stream(ctx, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reader.Close()
+
+	streamed, err := reader.Next()
+
+	if err != nil || streamed.TemperatureF != 79 || !streamed.Time.Equal(valid) {
+		t.Errorf("Expected streamed observation. Got %v %v", streamed, err)
+	}
+
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("Expected EOF. Got %v", err)
+	}
+
+	networks.AssertCalled(t, 1)
+	stations.AssertGetStationCalled(t, "LNK")
+	stations.AssertGetStationsCalled(t, "IA_ASOS")
+	weather.AssertCalled(t, 2)
+	weather.AssertCalledWithStations(t, "LNK")
+	weather.AssertCalledWith(t, "data", "tmpf")
+}
+
+func TestAssertionFailures(t *testing.T) {
+	weather := NewWeatherService()
+	weather.Get(context.Background(), iem.NewWeatherDataQuery().Stations("LNK", "OMA").Data(iem.All))
+
+	tb := &recordingTB{TB: t}
+
+	weather.AssertCalledWithStations(tb, "OMA", "LNK")
+	weather.AssertCalledWithStations(tb, "LNK")
+	NewStationService().AssertGetStationCalled(tb, "LNK")
+	NewNetworkService().AssertCalled(tb, 1)
+
+	if len(tb.failures) != 3 {
+		t.Errorf("Expected 3 failures. Got %v", tb.failures)
+	}
+}
+
+func TestErrorsAndDelay(t *testing.T) {
+	weather := NewWeatherService()
+	query := iem.NewWeatherDataQuery().Stations("LNK").Data(iem.All)
+	ctx := context.Background()
+	failure := errors.New("unavailable")
+
+	weather.ReturnNext()
+	weather.FailNext(failure)
+
+	if _, err := weather.Get(ctx, query); !errors.Is(err, failure) {
+		t.Errorf("Expected scripted error. Got %v", err)
+	}
+
+	if _, err := weather.Get(ctx, query); err != nil {
+		t.Errorf("Expected scripted error to be used once. Got %v", err)
+	}
+
+	if _, err := weather.Get(ctx, iem.NewWeatherDataQuery()); err == nil {
+		t.Error("Expected invalid query error")
+	}
+
+	weather.ReturnError(failure)
+
+	if _, err := weather.Stream(ctx, query); !errors.Is(err, failure) {
+		t.Errorf("Expected error. Got %v", err)
+	}
+
+	weather.ReturnError(nil)
+	weather.SetDelay(time.Second)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := weather.Get(ctx, query); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded. Got %v", err)
+	}
+}
+
+func TestStreamMatchesGet(t *testing.T) {
+	weather := NewWeatherService()
+	ctx := context.Background()
+	query := iem.NewWeatherDataQuery().Stations("LNK").Data(iem.TempF, iem.PrecipInch, iem.METAR)
+
+	valid := time.Date(2023, 1, 4, 1, 10, 0, 0, time.UTC)
+	zero := &iem.IEMWeatherData{Station: "LNK", Time: &valid, METAR: "KLNK 040110Z"}
+	zero.SetFloat(iem.TempF, 0)
+	zero.SetTrace(iem.PrecipInch)
+	missing := &iem.IEMWeatherData{Station: "LNK", Time: &valid}
+	missing.SetMissing(iem.TempF)
+
+	weather.Return(zero, missing)
+
+	data, err := weather.Get(ctx, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := weather.Stream(ctx, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streamed := []*iem.IEMWeatherData{}
+
+	for {
+		d, err := reader.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streamed = append(streamed, d)
+	}
+
+	if !reflect.DeepEqual(data, streamed) {
+		t.Errorf("Expected Stream to read what Get returns. Got %v and %v", data, streamed)
+	}
+}
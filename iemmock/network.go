@@ -0,0 +1,63 @@
+package iemmock
+
+import (
+	"context"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+// NetworkService is a mock iem.NetworkService
+type NetworkService struct {
+	base
+
+	networks []*iem.Network
+	calls    int
+}
+
+var _ iem.NetworkService = (*NetworkService)(nil)
+
+// NewNetworkService creates a NetworkService that returns no networks
+func NewNetworkService() *NetworkService {
+	return &NetworkService{}
+}
+
+// Return sets the networks GetNetworks returns
+func (s *NetworkService) Return(networks ...*iem.Network) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.networks = networks
+}
+
+func (s *NetworkService) GetNetworks(ctx context.Context) ([]*iem.Network, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+
+	if err := s.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*iem.Network{}, s.networks...), nil
+}
+
+// Calls to GetNetworks
+func (s *NetworkService) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+// AssertCalled fails the test when GetNetworks was not called n times
+func (s *NetworkService) AssertCalled(tb testing.TB, n int) {
+	tb.Helper()
+
+	if calls := s.Calls(); calls != n {
+		tb.Errorf("iemmock: expected GetNetworks to be called %d times. Got %d", n, calls)
+	}
+}
@@ -0,0 +1,134 @@
+package iemmock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+// StationService is a mock iem.StationService that serves added stations.
+// Stations and networks without stations are not found like they are by IEM
+type StationService struct {
+	base
+
+	stations         []*iem.Station
+	getStationCalls  []string
+	getStationsCalls []string
+}
+
+var _ iem.StationService = (*StationService)(nil)
+
+// NewStationService creates a StationService without stations
+func NewStationService() *StationService {
+	return &StationService{}
+}
+
+// AddStations adds stations that GetStation and GetStations return
+func (s *StationService) AddStations(stations ...*iem.Station) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stations = append(s.stations, stations...)
+}
+
+func notFound(detail string) iem.IEMNotFoundError {
+	return iem.IEMNotFoundError{Detail: detail, Code: 404}
+}
+
+func (s *StationService) GetStation(ctx context.Context, stationId string) (*iem.Station, error) {
+	s.mu.Lock()
+	s.getStationCalls = append(s.getStationCalls, stationId)
+	s.mu.Unlock()
+
+	if err := s.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, station := range s.stations {
+		if station.Id == stationId {
+			return station, nil
+		}
+	}
+
+	return nil, notFound(fmt.Sprintf("Station %s not found", stationId))
+}
+
+func (s *StationService) GetStations(ctx context.Context, networkId string) ([]*iem.Station, error) {
+	s.mu.Lock()
+	s.getStationsCalls = append(s.getStationsCalls, networkId)
+	s.mu.Unlock()
+
+	if err := s.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stations := []*iem.Station{}
+
+	for _, station := range s.stations {
+		if station.Network == networkId {
+			stations = append(stations, station)
+		}
+	}
+
+	if len(stations) == 0 {
+		return nil, notFound(fmt.Sprintf("Network %s not found", networkId))
+	}
+
+	return stations, nil
+}
+
+// GetStationCalls are the station ids GetStation was called with
+func (s *StationService) GetStationCalls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.getStationCalls...)
+}
+
+// GetStationsCalls are the network ids GetStations was called with
+func (s *StationService) GetStationsCalls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.getStationsCalls...)
+}
+
+// AssertGetStationCalled fails the test when GetStation was not called with a station id
+func (s *StationService) AssertGetStationCalled(tb testing.TB, stationId string) {
+	tb.Helper()
+
+	calls := s.GetStationCalls()
+
+	if !contains(calls, stationId) {
+		tb.Errorf("iemmock: expected GetStation to be called with %s. Got %v", stationId, calls)
+	}
+}
+
+// AssertGetStationsCalled fails the test when GetStations was not called with a network id
+func (s *StationService) AssertGetStationsCalled(tb testing.TB, networkId string) {
+	tb.Helper()
+
+	calls := s.GetStationsCalls()
+
+	if !contains(calls, networkId) {
+		tb.Errorf("iemmock: expected GetStations to be called with %s. Got %v", networkId, calls)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
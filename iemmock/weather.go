@@ -0,0 +1,164 @@
+package iemmock
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+// WeatherService is a mock iem.WeatherService that returns scripted observations
+type WeatherService struct {
+	base
+
+	data    []*iem.IEMWeatherData
+	queue   [][]*iem.IEMWeatherData
+	queries []*iem.WeatherDataQueryBuilder
+}
+
+var _ iem.WeatherService = (*WeatherService)(nil)
+var _ iem.WeatherStreamer = (*WeatherService)(nil)
+
+// NewWeatherService creates a WeatherService that returns no observations
+func NewWeatherService() *WeatherService {
+	return &WeatherService{}
+}
+
+// Return sets the observations every call returns
+func (s *WeatherService) Return(data ...*iem.IEMWeatherData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data = data
+}
+
+// ReturnNext sets the observations the next call returns, before those set by Return.
+// Each call to ReturnNext scripts one more call
+func (s *WeatherService) ReturnNext(data ...*iem.IEMWeatherData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queue = append(s.queue, data)
+}
+
+// Records a query and returns the observations or error of the call
+func (s *WeatherService) call(ctx context.Context, query *iem.WeatherDataQueryBuilder) ([]*iem.IEMWeatherData, error) {
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+
+	if err := s.begin(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) > 0 {
+		data := s.queue[0]
+		s.queue = s.queue[1:]
+
+		return data, nil
+	}
+
+	return s.data, nil
+}
+
+func (s *WeatherService) Get(ctx context.Context, query *iem.WeatherDataQueryBuilder) ([]*iem.IEMWeatherData, error) {
+	if _, err := query.BuildUrl(); err != nil {
+		return nil, err
+	}
+
+	data, err := s.call(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*iem.IEMWeatherData{}, data...), nil
+}
+
+// Stream reads the same scripted observations Get returns
+func (s *WeatherService) Stream(ctx context.Context, query *iem.WeatherDataQueryBuilder) (*iem.WeatherDataReader, error) {
+	if _, err := query.BuildUrl(); err != nil {
+		return nil, err
+	}
+
+	data, err := s.call(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return iem.NewWeatherDataSliceReader(data, query), nil
+}
+
+// Queries Get and Stream were called with
+func (s *WeatherService) Queries() []*iem.WeatherDataQueryBuilder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*iem.WeatherDataQueryBuilder{}, s.queries...)
+}
+
+// Url values of the queries calls were made with
+func (s *WeatherService) queryValues() []url.Values {
+	values := []url.Values{}
+
+	for _, query := range s.Queries() {
+		v, _ := query.BuildUrl()
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// Compares values ignoring order
+func sameValues(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+// AssertCalled fails the test when Get and Stream were not called n times in total
+func (s *WeatherService) AssertCalled(tb testing.TB, n int) {
+	tb.Helper()
+
+	if calls := len(s.Queries()); calls != n {
+		tb.Errorf("iemmock: expected weather to be requested %d times. Got %d", n, calls)
+	}
+}
+
+// AssertCalledWith fails the test when no query had exactly the values for
+// an asos.py url query key, such as "data" or "tz"
+func (s *WeatherService) AssertCalledWith(tb testing.TB, key string, values ...string) {
+	tb.Helper()
+
+	called := [][]string{}
+
+	for _, v := range s.queryValues() {
+		if sameValues(v[key], values) {
+			return
+		}
+
+		called = append(called, v[key])
+	}
+
+	tb.Errorf("iemmock: expected weather to be requested with %s=%v. Got %v", key, values, called)
+}
+
+// AssertCalledWithStations fails the test when no query requested exactly the stations
+func (s *WeatherService) AssertCalledWithStations(tb testing.TB, stations ...string) {
+	tb.Helper()
+	s.AssertCalledWith(tb, "station", stations...)
+}
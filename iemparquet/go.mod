@@ -0,0 +1,24 @@
+module github.com/colevoss/go-iem-sdk/iemparquet
+
+go 1.21.1
+
+require (
+	github.com/colevoss/go-iem-sdk v0.0.0-20261019055611-ce782d7adc42
+	github.com/parquet-go/parquet-go v0.23.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mattn/go-runewidth v0.0.15 // indirect
+	github.com/olekukonko/tablewriter v0.0.5 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	github.com/segmentio/encoding v0.4.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
+
+// Builds against the SDK in this repository. Modules depending on this one
+// ignore the replace and use the required version of the SDK
+replace github.com/colevoss/go-iem-sdk => ../
@@ -0,0 +1,423 @@
+// Package iemparquet writes and reads IEMWeatherData as Apache Parquet. It is
+// a separate module so the SDK does not depend on parquet-go
+//
+//	go get github.com/colevoss/go-iem-sdk/iemparquet
+package iemparquet
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Number of rows buffered before they are written
+const rowBufferSize = 1024
+
+// column maps an IEMWeatherData field to a parquet column
+type column struct {
+	name string
+	node parquet.Node
+	get  func(d *iem.IEMWeatherData) (parquet.Value, bool) // False when the value is missing
+	set  func(d *iem.IEMWeatherData, v parquet.Value)
+}
+
+func dictionaryString() parquet.Node {
+	return parquet.Encoded(parquet.String(), &parquet.RLEDictionary)
+}
+
+func timestamp() parquet.Node {
+	return parquet.Timestamp(parquet.Microsecond)
+}
+
+func timeValue(t *time.Time) (parquet.Value, bool) {
+	if t == nil {
+		return parquet.Value{}, false
+	}
+
+	return parquet.Int64Value(t.UnixMicro()), true
+}
+
+func setTime(t **time.Time, v parquet.Value) {
+	value := time.UnixMicro(v.Int64()).UTC()
+	*t = &value
+}
+
+func stringValue(s string) (parquet.Value, bool) {
+	if s == "" {
+		return parquet.Value{}, false
+	}
+
+	return parquet.ByteArrayValue([]byte(s)), true
+}
+
+func stringColumn(name string, node parquet.Node, field func(d *iem.IEMWeatherData) *string) *column {
+	return &column{
+		name: name,
+		node: parquet.Optional(node),
+		get: func(d *iem.IEMWeatherData) (parquet.Value, bool) {
+			return stringValue(*field(d))
+		},
+		set: func(d *iem.IEMWeatherData, v parquet.Value) {
+			*field(d) = string(v.ByteArray())
+		},
+	}
+}
+
+func metadataColumn(name string) *column {
+	switch name {
+	case "lon":
+		return floatColumn(iem.Longitude)
+	case "lat":
+		return floatColumn(iem.Latitude)
+	case "elevation":
+		return stringColumn(name, parquet.String(), func(d *iem.IEMWeatherData) *string { return &d.Elevation })
+	}
+
+	return nil
+}
+
+func floatColumn(c iem.WeatherDataData) *column {
+	return &column{
+		name: string(c),
+		node: parquet.Optional(parquet.Leaf(parquet.DoubleType)),
+		get: func(d *iem.IEMWeatherData) (parquet.Value, bool) {
+			if d.IsMissing(c) {
+				return parquet.Value{}, false
+			}
+
+			// Traces are written as 0 and listed in the trace column
+			value, _ := d.Float(c)
+			return parquet.DoubleValue(value), true
+		},
+		set: func(d *iem.IEMWeatherData, v parquet.Value) {
+			d.SetFloat(c, v.Double())
+		},
+	}
+}
+
+func dataColumn(c iem.WeatherDataData) *column {
+	name := string(c)
+
+	switch c {
+	case iem.CloudCoverageL1:
+		return stringColumn(name, dictionaryString(), func(d *iem.IEMWeatherData) *string { return &d.CloudCoverageL1 })
+	case iem.CloudCoverageL2:
+		return stringColumn(name, dictionaryString(), func(d *iem.IEMWeatherData) *string { return &d.CloudCoverageL2 })
+	case iem.CloudCoverageL3:
+		return stringColumn(name, dictionaryString(), func(d *iem.IEMWeatherData) *string { return &d.CloudCoverageL3 })
+	case iem.PresentWeatherCodes:
+		return stringColumn(name, dictionaryString(), func(d *iem.IEMWeatherData) *string { return &d.PresentWeatherCodes })
+	case iem.METAR:
+		return stringColumn(name, parquet.String(), func(d *iem.IEMWeatherData) *string { return &d.METAR })
+	case iem.PeakWindTime:
+		return &column{
+			name: name,
+			node: parquet.Optional(timestamp()),
+			get: func(d *iem.IEMWeatherData) (parquet.Value, bool) {
+				return timeValue(d.PeakWindTime)
+			},
+			set: func(d *iem.IEMWeatherData, v parquet.Value) {
+				setTime(&d.PeakWindTime, v)
+			},
+		}
+	}
+
+	return floatColumn(c)
+}
+
+// Name of the column listing the trace columns of a row
+const traceColumnName = "trace"
+
+// traceColumn lists the numeric columns of a row that are trace amounts,
+// separated by commas. It is null when a row has no traces
+func traceColumn(columns []iem.WeatherDataData) *column {
+	return &column{
+		name: traceColumnName,
+		node: parquet.Optional(dictionaryString()),
+		get: func(d *iem.IEMWeatherData) (parquet.Value, bool) {
+			traces := []string{}
+
+			for _, c := range columns {
+				if d.IsTrace(c) {
+					traces = append(traces, string(c))
+				}
+			}
+
+			return stringValue(strings.Join(traces, ","))
+		},
+		set: func(d *iem.IEMWeatherData, v parquet.Value) {
+			for _, c := range strings.Split(string(v.ByteArray()), ",") {
+				d.SetTrace(iem.WeatherDataData(c))
+			}
+		},
+	}
+}
+
+var stationColumn = &column{
+	name: "station",
+	node: dictionaryString(),
+	get: func(d *iem.IEMWeatherData) (parquet.Value, bool) {
+		return parquet.ByteArrayValue([]byte(d.Station)), true
+	},
+	set: func(d *iem.IEMWeatherData, v parquet.Value) {
+		d.Station = string(v.ByteArray())
+	},
+}
+
+var timeColumn = &column{
+	name: "valid",
+	node: parquet.Optional(timestamp()),
+	get: func(d *iem.IEMWeatherData) (parquet.Value, bool) {
+		return timeValue(d.Time)
+	},
+	set: func(d *iem.IEMWeatherData, v parquet.Value) {
+		setTime(&d.Time, v)
+	},
+}
+
+// All columns that can be read, by name
+func allColumns() map[string]*column {
+	columns := map[string]*column{
+		stationColumn.name: stationColumn,
+		timeColumn.name:    timeColumn,
+	}
+
+	for _, name := range []string{"lon", "lat", "elevation"} {
+		columns[name] = metadataColumn(name)
+	}
+
+	all := iem.NewWeatherDataQuery().Data(iem.All).Columns()
+
+	for _, c := range all {
+		columns[string(c)] = dataColumn(c)
+	}
+
+	columns[traceColumnName] = traceColumn(all)
+
+	return columns
+}
+
+// Schema is the parquet schema of the columns returned by a query. Measurements
+// are optional doubles that are null when missing, valid and peak_wind_time are
+// UTC timestamps and the station and sky cover columns are dictionary encoded.
+// Traces are written as 0 and the trace column lists the columns of a row that
+// are traces, such as "p01i,ice_accretion_1hr"
+func Schema(query *iem.WeatherDataQueryBuilder) *parquet.Schema {
+	schema, _ := querySchema(query)
+	return schema
+}
+
+// Schema and columns of a query in the schema's column order
+func querySchema(query *iem.WeatherDataQueryBuilder) (*parquet.Schema, []*column) {
+	group := parquet.Group{}
+	byName := map[string]*column{}
+
+	add := func(c *column) {
+		group[c.name] = c.node
+		byName[c.name] = c
+	}
+
+	add(stationColumn)
+	add(timeColumn)
+
+	for _, name := range query.MetadataColumns() {
+		add(metadataColumn(name))
+	}
+
+	for _, c := range query.Columns() {
+		add(dataColumn(c))
+	}
+
+	add(traceColumn(query.Columns()))
+
+	schema := parquet.NewSchema("iem_weather_data", group)
+	columns := []*column{}
+
+	for _, path := range schema.Columns() {
+		columns = append(columns, byName[path[0]])
+	}
+
+	return schema, columns
+}
+
+// Writer writes weather data as parquet with the columns of a query
+type Writer struct {
+	writer  *parquet.Writer
+	columns []*column
+	rows    []parquet.Row
+}
+
+// Creates a Writer of the columns returned by a query. The writer must be
+// closed to write the parquet footer
+func NewWriter(w io.Writer, query *iem.WeatherDataQueryBuilder) *Writer {
+	schema, columns := querySchema(query)
+
+	return &Writer{
+		writer:  parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy)),
+		columns: columns,
+		rows:    make([]parquet.Row, 0, rowBufferSize),
+	}
+}
+
+func (w *Writer) row(d *iem.IEMWeatherData) parquet.Row {
+	row := make(parquet.Row, len(w.columns))
+
+	for i, c := range w.columns {
+		value, ok := c.get(d)
+
+		definitionLevel := 0
+
+		if ok && c.node.Optional() {
+			definitionLevel = 1
+		}
+
+		if !ok {
+			value = parquet.Value{}
+		}
+
+		row[i] = value.Level(0, definitionLevel, i)
+	}
+
+	return row
+}
+
+// Write buffers observations and writes them when the buffer is full
+func (w *Writer) Write(data ...*iem.IEMWeatherData) error {
+	for _, d := range data {
+		w.rows = append(w.rows, w.row(d))
+
+		if len(w.rows) == cap(w.rows) {
+			if err := w.flushRows(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteFrom writes every observation of a streaming reader
+func (w *Writer) WriteFrom(reader *iem.WeatherDataReader) error {
+	for {
+		d, err := reader.Next()
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := w.Write(d); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *Writer) flushRows() error {
+	_, err := w.writer.WriteRows(w.rows)
+	w.rows = w.rows[:0]
+
+	return err
+}
+
+// Close writes any buffered observations and the parquet footer
+func (w *Writer) Close() error {
+	if err := w.flushRows(); err != nil {
+		return err
+	}
+
+	return w.writer.Close()
+}
+
+// WriteWeatherData writes observations as parquet with the columns of a query
+func WriteWeatherData(w io.Writer, data []*iem.IEMWeatherData, query *iem.WeatherDataQueryBuilder) error {
+	writer := NewWriter(w, query)
+
+	if err := writer.Write(data...); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// WriteStream writes every observation of a streaming reader as parquet with
+// the columns of the reader's query
+func WriteStream(w io.Writer, reader *iem.WeatherDataReader) error {
+	writer := NewWriter(w, reader.Query())
+
+	if err := writer.WriteFrom(reader); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// ReadWeatherData reads weather data written by a Writer. Null values are
+// missing (IsMissing) and the columns listed in the trace column are traces
+// (IsTrace). Times are read in UTC
+func ReadWeatherData(r io.ReaderAt, size int64) ([]*iem.IEMWeatherData, error) {
+	file, err := parquet.OpenFile(r, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	known := allColumns()
+	columns := []*column{}
+
+	for _, path := range file.Schema().Columns() {
+		columns = append(columns, known[path[0]])
+	}
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+
+	data := []*iem.IEMWeatherData{}
+	rows := make([]parquet.Row, rowBufferSize)
+
+	for {
+		n, err := reader.ReadRows(rows)
+
+		for _, row := range rows[:n] {
+			d := &iem.IEMWeatherData{}
+			var traces parquet.Value
+
+			for _, value := range row {
+				c := columns[value.Column()]
+
+				switch {
+				case c == nil:
+				case c.name == traceColumnName:
+					// Traces are set once the row's values are, so the 0 read
+					// for a trace column does not mark it observed
+					traces = value
+				case value.IsNull():
+					// Null values are missing rather than 0
+					d.SetMissing(iem.WeatherDataData(c.name))
+				default:
+					c.set(d, value)
+				}
+			}
+
+			if !traces.IsNull() {
+				known[traceColumnName].set(d, traces)
+			}
+
+			data = append(data, d)
+		}
+
+		if errors.Is(err, io.EOF) {
+			return data, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
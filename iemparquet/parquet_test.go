@@ -0,0 +1,147 @@
+package iemparquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+func readFixture(t *testing.T, query *iem.WeatherDataQueryBuilder) ([]*iem.IEMWeatherData, []byte) {
+	t.Helper()
+
+	fixture, err := os.ReadFile("../data/full_weather_data.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := iem.ParseWeatherData(bytes.NewReader(fixture), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data, fixture
+}
+
+func assertSameData(t *testing.T, expected []*iem.IEMWeatherData, actual []*iem.IEMWeatherData) {
+	t.Helper()
+
+	// Times are read back in UTC so compare the JSON encodings
+	e, _ := json.Marshal(expected)
+	a, _ := json.Marshal(actual)
+
+	if !bytes.Equal(e, a) {
+		t.Errorf("expected\n%s\ngot\n%s", e, a)
+	}
+}
+
+func TestWriteWeatherDataRoundTrip(t *testing.T) {
+	query := iem.NewWeatherDataQuery().Data(iem.All).LatLon(true).Elevation(true)
+	data, _ := readFixture(t, query)
+
+	var buf bytes.Buffer
+
+	if err := WriteWeatherData(&buf, data, query); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadWeatherData(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSameData(t, data, read)
+}
+
+func TestWriteStreamRequestedColumns(t *testing.T) {
+	query := iem.NewWeatherDataQuery().Data(iem.TempF, iem.PrecipInch, iem.CloudCoverageL1)
+	data, fixture := readFixture(t, query)
+
+	var buf bytes.Buffer
+
+	if err := WriteStream(&buf, iem.NewWeatherDataReader(bytes.NewReader(fixture), query)); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := Schema(query)
+
+	if len(schema.Columns()) != 6 {
+		t.Errorf("expected 6 columns, got %v", schema.Columns())
+	}
+
+	if _, ok := schema.Lookup("skyc1"); !ok {
+		t.Error("expected skyc1 column")
+	}
+
+	read, err := ReadWeatherData(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The CSV writer also only writes the requested columns
+	var requested bytes.Buffer
+
+	if err := iem.WriteWeatherData(&requested, data, query); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := iem.ParseWeatherData(&requested, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSameData(t, expected, read)
+}
+
+func TestWriteWeatherDataMissingAndTrace(t *testing.T) {
+	query := iem.NewWeatherDataQuery().Data(iem.TempF, iem.DewPointF, iem.Visibility, iem.PrecipInch, iem.IceAccretion1HR).LatLon(true)
+	data, err := iem.ParseWeatherData(strings.NewReader("station,valid,lon,lat,tmpf,dwpf,vsby,p01i,ice_accretion_1hr\nLNK,2023-01-04 01:10,-96.76,M,0.00,M,T,T,0.00\n"), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := WriteWeatherData(&buf, data, query); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadWeatherData(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := read[0]
+
+	if d.IsMissing(iem.TempF) || d.IsMissing(iem.IceAccretion1HR) || d.IsTrace(iem.IceAccretion1HR) {
+		t.Error("expected observed zeros")
+	}
+
+	if !d.IsMissing(iem.DewPointF) || !d.IsMissing(iem.Latitude) || d.IsMissing(iem.Longitude) {
+		t.Error("expected missing dwpf and lat")
+	}
+
+	if !d.IsTrace(iem.PrecipInch) || !d.IsTrace(iem.Visibility) {
+		t.Error("expected p01i and vsby traces")
+	}
+
+	var written bytes.Buffer
+
+	if err := iem.WriteWeatherData(&written, read, query); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(written.String(), "LNK,2023-01-04 01:10,-96.7600,M,0.00,M,T,T,0.00\n") {
+		t.Errorf("unexpected output %q", written.String())
+	}
+}
@@ -0,0 +1,232 @@
+package iem
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IEMREPoint is a location on the IEM Reanalysis grid
+type IEMREPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// StationPoint is the IEMRE point at a station's location
+func StationPoint(station *Station) IEMREPoint {
+	return IEMREPoint{Lat: station.Latitude, Lon: station.Longitude}
+}
+
+func (p IEMREPoint) path() string {
+	return fmt.Sprintf(
+		"%s/%s",
+		strconv.FormatFloat(p.Lat, 'f', 4, 64),
+		strconv.FormatFloat(p.Lon, 'f', 4, 64),
+	)
+}
+
+// IEMREDay is the reanalysis of a day at a point.
+// Properties are nil when the reanalysis has no value for the day
+type IEMREDay struct {
+	Date time.Time `json:"date"`
+
+	HighF            *float64 `json:"daily_high_f,omitempty"`    // High Temperature [F]
+	LowF             *float64 `json:"daily_low_f,omitempty"`     // Low Temperature [F]
+	AvgDewPointF     *float64 `json:"avg_dewpoint_f,omitempty"`  // Average Dew Point [F]
+	PrecipInch       *float64 `json:"daily_precip_in,omitempty"` // Precipitation [inch]
+	SolarRadiationMJ *float64 `json:"srad_mj,omitempty"`         // Solar Radiation [MJ/m2]
+
+	ClimateHighF      *float64 `json:"climate_daily_high_f,omitempty"`    // Average High Temperature [F]
+	ClimateLowF       *float64 `json:"climate_daily_low_f,omitempty"`     // Average Low Temperature [F]
+	ClimatePrecipInch *float64 `json:"climate_daily_precip_in,omitempty"` // Average Precipitation [inch]
+}
+
+// IEMREHour is the reanalysis of an hour at a point.
+// Properties are nil when the reanalysis has no value for the hour
+type IEMREHour struct {
+	Time time.Time `json:"time"` // Valid time (UTC)
+
+	TemperatureF  *float64 `json:"air_temp_f,omitempty"`       // Air Temperature [F]
+	DewPointF     *float64 `json:"dew_point_f,omitempty"`      // Dew Point [F]
+	CloudCoverage *float64 `json:"skyc_%,omitempty"`           // Cloud Coverage [%]
+	WindUMPS      *float64 `json:"uwnd_mps,omitempty"`         // Eastward Wind [m/s]
+	WindVMPS      *float64 `json:"vwnd_mps,omitempty"`         // Northward Wind [m/s]
+	PrecipInch    *float64 `json:"hourly_precip_in,omitempty"` // Precipitation [inch]
+}
+
+type iemREDailyJsonResponse struct {
+	Data []*struct {
+		IEMREDay
+		Date string `json:"date"`
+	} `json:"data"`
+}
+
+type iemREHourlyJsonResponse struct {
+	Data []*struct {
+		IEMREHour
+		ValidUTC string `json:"valid_utc"`
+	} `json:"data"`
+}
+
+// IEMREPointDays are the reanalysis days of a point in a batch query
+type IEMREPointDays struct {
+	Point IEMREPoint  `json:"point"`
+	Days  []*IEMREDay `json:"days"`
+}
+
+type IEMREService interface {
+	GetDaily(ctx context.Context, point IEMREPoint, date time.Time) (*IEMREDay, error)
+	GetMultiDay(ctx context.Context, point IEMREPoint, start time.Time, end time.Time) ([]*IEMREDay, error)
+	GetHourly(ctx context.Context, point IEMREPoint, date time.Time) ([]*IEMREHour, error)
+
+	// Gets the days between start and end for each point. The results are in
+	// the same order as points
+	GetMultiDayBatch(ctx context.Context, points []IEMREPoint, start time.Time, end time.Time) ([]*IEMREPointDays, error)
+}
+
+// Number of concurrent requests made by batch queries
+const iemreBatchConcurrency = 4
+
+type IEMIEMREService struct {
+	client *Client
+}
+
+func (s *IEMIEMREService) GetDaily(ctx context.Context, point IEMREPoint, date time.Time) (*IEMREDay, error) {
+	url := fmt.Sprintf("/iemre/daily/%s/%s/json", date.Format("2006-01-02"), point.path())
+
+	days, err := s.getDays(ctx, url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(days) == 0 {
+		return nil, IEMNotFoundError{
+			Detail: fmt.Sprintf("no reanalysis for %s at %s", date.Format("2006-01-02"), point.path()),
+			Code:   404,
+		}
+	}
+
+	return days[0], nil
+}
+
+func (s *IEMIEMREService) GetMultiDay(ctx context.Context, point IEMREPoint, start time.Time, end time.Time) ([]*IEMREDay, error) {
+	url := fmt.Sprintf(
+		"/iemre/multiday/%s/%s/%s/json",
+		start.Format("2006-01-02"),
+		end.Format("2006-01-02"),
+		point.path(),
+	)
+
+	return s.getDays(ctx, url)
+}
+
+func (s *IEMIEMREService) GetHourly(ctx context.Context, point IEMREPoint, date time.Time) ([]*IEMREHour, error) {
+	url := fmt.Sprintf("/iemre/hourly/%s/%s/json", date.Format("2006-01-02"), point.path())
+	var hourlyResponse iemREHourlyJsonResponse
+
+	err := s.client.getJson(ctx, url, &hourlyResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hours := []*IEMREHour{}
+
+	for _, h := range hourlyResponse.Data {
+		valid, err := parseIEMTime(h.ValidUTC)
+
+		if err != nil {
+			return nil, fmt.Errorf("error parsing valid_utc [%w]", err)
+		}
+
+		hour := h.IEMREHour
+		hour.Time = valid
+
+		hours = append(hours, &hour)
+	}
+
+	return hours, nil
+}
+
+func (s *IEMIEMREService) GetMultiDayBatch(ctx context.Context, points []IEMREPoint, start time.Time, end time.Time) ([]*IEMREPointDays, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*IEMREPointDays, len(points))
+	semaphore := make(chan struct{}, iemreBatchConcurrency)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var batchErr error
+
+	for i, point := range points {
+		wg.Add(1)
+
+		go func(i int, point IEMREPoint) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			defer func() { <-semaphore }()
+
+			days, err := s.GetMultiDay(ctx, point, start, end)
+
+			if err != nil {
+				once.Do(func() {
+					batchErr = err
+					cancel()
+				})
+
+				return
+			}
+
+			results[i] = &IEMREPointDays{Point: point, Days: days}
+		}(i, point)
+	}
+
+	wg.Wait()
+
+	if batchErr != nil {
+		return nil, batchErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *IEMIEMREService) getDays(ctx context.Context, url string) ([]*IEMREDay, error) {
+	var dailyResponse iemREDailyJsonResponse
+
+	err := s.client.getJson(ctx, url, &dailyResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	days := []*IEMREDay{}
+
+	for _, d := range dailyResponse.Data {
+		date, err := time.Parse("2006-01-02", d.Date)
+
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date [%w]", err)
+		}
+
+		day := d.IEMREDay
+		day.Date = date
+
+		days = append(days, &day)
+	}
+
+	return days, nil
+}
@@ -0,0 +1,175 @@
+package iem
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Serves the multiday fixture, failing for points whose path contains fail,
+// and records the most requests in flight at once
+type iemreFixtureServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	inFlight int
+	maxLoad  int
+}
+
+func newIEMREFixtureServer(t *testing.T, fail string) *iemreFixtureServer {
+	t.Helper()
+
+	body, err := os.ReadFile("./data/iemre_multiday.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &iemreFixtureServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.inFlight++
+		s.maxLoad = max(s.maxLoad, s.inFlight)
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			s.inFlight--
+			s.mu.Unlock()
+		}()
+
+		if fail != "" && strings.Contains(r.URL.Path, fail) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Point outside of domain"}`))
+			return
+		}
+
+		select {
+		case <-time.After(20 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+
+		w.Write(body)
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func iemrePoints(n int) []IEMREPoint {
+	points := []IEMREPoint{}
+
+	for i := 0; i < n; i++ {
+		points = append(points, IEMREPoint{Lat: 41 + float64(i)/10, Lon: -93.5})
+	}
+
+	return points
+}
+
+func TestGetMultiDay(t *testing.T) {
+	server := newIEMREFixtureServer(t, "")
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+
+	start := time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC)
+	days, err := client.IEMRE().GetMultiDay(context.Background(), IEMREPoint{Lat: 42.0308, Lon: -93.6319}, start, start.AddDate(0, 0, 2))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.requests[0] != "/iemre/multiday/2023-10-03/2023-10-05/42.0308/-93.6319/json" {
+		t.Errorf("unexpected path %s", server.requests[0])
+	}
+
+	if len(days) != 3 || !days[1].Date.Equal(start.AddDate(0, 0, 1)) || *days[1].PrecipInch != 0.58 {
+		t.Fatalf("unexpected days %+v", days)
+	}
+
+	// Null values are nil rather than 0
+	if days[2].HighF != nil || days[2].SolarRadiationMJ != nil || *days[2].LowF != 48.2 {
+		t.Errorf("unexpected day with null values %+v", days[2])
+	}
+}
+
+func TestGetMultiDayBatch(t *testing.T) {
+	server := newIEMREFixtureServer(t, "")
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+
+	points := iemrePoints(10)
+	start := time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC)
+
+	results, err := client.IEMRE().GetMultiDayBatch(context.Background(), points, start, start.AddDate(0, 0, 2))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.requests) != len(points) {
+		t.Errorf("expected %d requests, got %d", len(points), len(server.requests))
+	}
+
+	if server.maxLoad > iemreBatchConcurrency {
+		t.Errorf("expected at most %d concurrent requests, got %d", iemreBatchConcurrency, server.maxLoad)
+	}
+
+	// Results are in the order of the points
+	for i, result := range results {
+		if result.Point != points[i] || len(result.Days) != 3 {
+			t.Errorf("unexpected result %d %+v", i, result)
+		}
+	}
+}
+
+func TestGetMultiDayBatchError(t *testing.T) {
+	points := iemrePoints(10)
+
+	// The first point fails while the next points are in flight
+	server := newIEMREFixtureServer(t, points[0].path())
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+
+	start := time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC)
+	results, err := client.IEMRE().GetMultiDayBatch(context.Background(), points, start, start.AddDate(0, 0, 2))
+
+	var notFound IEMNotFoundError
+
+	if !errors.As(err, &notFound) || notFound.Detail != "Point outside of domain" {
+		t.Fatalf("expected the failed point's error, got %v", err)
+	}
+
+	if results != nil {
+		t.Errorf("expected no results, got %v", results)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	// Points waiting for a request are canceled
+	if len(server.requests) >= len(points) {
+		t.Errorf("expected the batch to stop after the error, got %d requests", len(server.requests))
+	}
+}
+
+func TestGetMultiDayBatchCanceled(t *testing.T) {
+	server := newIEMREFixtureServer(t, "")
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC)
+
+	if _, err := client.IEMRE().GetMultiDayBatch(ctx, iemrePoints(3), start, start); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+}
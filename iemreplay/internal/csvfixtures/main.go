@@ -0,0 +1,79 @@
+// Command csvfixtures converts the asos.py CSV files in the data directory to
+// replay fixtures of the requests that return them.
+//
+// Usage:
+//
+//	csvfixtures <data dir> <fixtures dir>
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+	"github.com/colevoss/go-iem-sdk/iemreplay"
+)
+
+// Queries that return the CSV files. iceland_stations.csv has no query since it
+// is the CSV output of networks.php (stid, station_name, lat, lon, elev,
+// begints, iem_network), which the Client never requests
+var csvQueries = map[string]func() *iem.WeatherDataQueryBuilder{
+	"full_weather_data.csv": func() *iem.WeatherDataQueryBuilder {
+		return iem.NewWeatherDataQuery().
+			Stations("LNK").
+			Data(iem.All).
+			Start(time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)).
+			End(time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC)).
+			LatLon(true).
+			Elevation(true)
+	},
+	"partial_weather_data.csv": func() *iem.WeatherDataQueryBuilder {
+		return iem.NewWeatherDataQuery().
+			Stations("LNK").
+			Data(iem.TempF, iem.TempC, iem.RelativeHumidity, iem.WindDirection, iem.SeaLevelPressure, iem.PrecipInch).
+			Start(time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)).
+			End(time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC))
+	},
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "Usage: csvfixtures <data dir> <fixtures dir>")
+		os.Exit(2)
+	}
+
+	if err := convert(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func convert(dataDir string, fixturesDir string) error {
+	for name, query := range csvQueries {
+		body, err := os.ReadFile(filepath.Join(dataDir, name))
+
+		if err != nil {
+			return err
+		}
+
+		v, err := query().BuildUrl()
+
+		if err != nil {
+			return err
+		}
+
+		header := http.Header{}
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+
+		f := iemreplay.NewFixture(http.MethodGet, "/cgi-bin/request/asos.py?"+v.Encode(), http.StatusOK, header, body)
+
+		if err := iemreplay.WriteFixture(fixturesDir, f); err != nil {
+			return fmt.Errorf("%s [%w]", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+// Package iemreplay records responses from IEM to fixture files and replays
+// them, so code that uses an iem.Client can be tested offline.
+//
+// Fixtures are keyed by the request's path and normalized query, so they are
+// replayed for the same request made in any order of query values and
+// against any base url. Record fixtures by running tests with IEM_RECORD=1:
+//
+//	client := iem.NewClientWithOptions(iemreplay.ForTest(t, "testdata/iem"))
+//
+// Fixtures converted from the repository's data/*.csv files are in
+// data/fixtures and are regenerated with go generate.
+package iemreplay
+
+//go:generate go run ./internal/csvfixtures ../data ../data/fixtures
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+// Mode of a Transport
+type Mode int
+
+const (
+	// Replay serves recorded fixtures and fails requests that were not recorded
+	Replay Mode = iota
+
+	// Record sends requests to IEM and saves their responses as fixtures
+	Record
+)
+
+// Environment variable that sets ModeFromEnv to Record when it is not empty
+const RecordEnv = "IEM_RECORD"
+
+// ModeFromEnv is Record when IEM_RECORD is set and Replay otherwise
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return Record
+	}
+
+	return Replay
+}
+
+// Fixture is a recorded response to a request
+type Fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"` // Path and normalized query
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+
+	// Body is stored as text when it is valid UTF-8 and base64 otherwise
+	Body       string `json:"body,omitempty"`
+	BodyBase64 []byte `json:"body_base64,omitempty"`
+}
+
+// Key of a request url that fixtures are stored and found by. The scheme and
+// host are dropped and query values are sorted
+func Key(requestUrl string) string {
+	u, err := url.Parse(iem.NormalizeURL(requestUrl))
+
+	if err != nil {
+		return requestUrl
+	}
+
+	return u.RequestURI()
+}
+
+// Name of the fixture file of a method and key. Readable from the path and
+// unique by a hash of the key
+func fileName(method string, key string) string {
+	sum := sha256.Sum256([]byte(method + " " + key))
+	path := key
+
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	path = strings.NewReplacer("/", "_", ".", "_").Replace(strings.Trim(path, "/"))
+
+	return fmt.Sprintf("%s-%s.json", path, hex.EncodeToString(sum[:8]))
+}
+
+// NewFixture creates a fixture of a response to a request url
+func NewFixture(method string, requestUrl string, status int, header http.Header, body []byte) *Fixture {
+	f := &Fixture{
+		Method: method,
+		URL:    Key(requestUrl),
+		Status: status,
+		Header: header,
+	}
+
+	if utf8.Valid(body) {
+		f.Body = string(body)
+	} else {
+		f.BodyBase64 = body
+	}
+
+	return f
+}
+
+// Response of the fixture to a request
+func (f *Fixture) Response(req *http.Request) *http.Response {
+	body := []byte(f.Body)
+
+	if f.BodyBase64 != nil {
+		body = f.BodyBase64
+	}
+
+	header := f.Header.Clone()
+
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// WriteFixture writes a fixture to a directory
+func WriteFixture(dir string, f *Fixture) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, fileName(f.Method, f.URL)), append(data, '\n'), 0o644)
+}
+
+// ReadFixture reads the fixture of a request from a directory. The error is
+// os.ErrNotExist when the request was not recorded
+func ReadFixture(dir string, method string, requestUrl string) (*Fixture, error) {
+	key := Key(requestUrl)
+	data, err := os.ReadFile(filepath.Join(dir, fileName(method, key)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fixture
+
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	if f.URL != key {
+		return nil, fmt.Errorf("iemreplay: fixture for %s has url %s", key, f.URL)
+	}
+
+	return &f, nil
+}
+
+// UnrecordedError is returned when replaying a request that has no fixture
+type UnrecordedError struct {
+	Method string
+	URL    string
+	Dir    string
+}
+
+func (err *UnrecordedError) Error() string {
+	return fmt.Sprintf(
+		"iemreplay: no fixture for %s %s in %s (record it by running with %s=1)",
+		err.Method, err.URL, err.Dir, RecordEnv,
+	)
+}
+
+// Transport is an http.RoundTripper that records or replays fixtures
+type Transport struct {
+	Dir  string
+	Mode Mode
+
+	// Transport requests are sent with in Record mode. http.DefaultTransport when nil
+	Transport http.RoundTripper
+
+	tb         testing.TB
+	mu         sync.Mutex
+	unrecorded []string
+}
+
+// New creates a Transport that records to or replays from dir
+func New(dir string, mode Mode) *Transport {
+	return &Transport{Dir: dir, Mode: mode}
+}
+
+// ForTest creates a client option that replays fixtures from dir, or records
+// them when IEM_RECORD is set. Unrecorded requests fail the test
+func ForTest(tb testing.TB, dir string) iem.ClientOption {
+	t := New(dir, ModeFromEnv())
+	t.tb = tb
+
+	return t.Option()
+}
+
+// Option sets a client to send requests with the Transport
+func (t *Transport) Option() iem.ClientOption {
+	return iem.WithHTTPClient(&http.Client{Transport: t})
+}
+
+// Unrecorded requests that were replayed without a fixture
+func (t *Transport) Unrecorded() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string{}, t.unrecorded...)
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Mode == Record {
+		return t.record(req)
+	}
+
+	f, err := ReadFixture(t.Dir, req.Method, req.URL.String())
+
+	if errors.Is(err, os.ErrNotExist) {
+		err := &UnrecordedError{Method: req.Method, URL: Key(req.URL.String()), Dir: t.Dir}
+
+		t.mu.Lock()
+		t.unrecorded = append(t.unrecorded, err.Method+" "+err.URL)
+		t.mu.Unlock()
+
+		if t.tb != nil {
+			t.tb.Error(err)
+		}
+
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Response(req), nil
+}
+
+func (t *Transport) record(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	f := NewFixture(req.Method, req.URL.String(), resp.StatusCode, header, body)
+
+	if err := WriteFixture(t.Dir, f); err != nil {
+		return nil, err
+	}
+
+	return f.Response(req), nil
+}
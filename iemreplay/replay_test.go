@@ -0,0 +1,135 @@
+package iemreplay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+const fixturesDir = "../data/fixtures"
+
+func TestReplayCSVFixtures(t *testing.T) {
+	client := iem.NewClientWithOptions(New(fixturesDir, Replay).Option())
+
+	query := iem.NewWeatherDataQuery().
+		Stations("LNK").
+		Data(iem.All).
+		Start(time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)).
+		End(time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC)).
+		LatLon(true).
+		Elevation(true)
+
+	data, err := client.Weather().Get(context.Background(), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open("../data/full_weather_data.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	expected, err := iem.ParseWeatherData(file, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := json.Marshal(expected)
+	b, _ := json.Marshal(data)
+
+	if string(a) != string(b) {
+		t.Error("Expected replayed weather data to equal data/full_weather_data.csv")
+	}
+}
+
+func TestReplayUnrecorded(t *testing.T) {
+	transport := New(t.TempDir(), Replay)
+	client := iem.NewClientWithOptions(transport.Option())
+
+	_, err := client.Networks().GetNetworks(context.Background())
+
+	var unrecorded *UnrecordedError
+
+	if !errors.As(err, &unrecorded) {
+		t.Fatalf("Expected UnrecordedError. Got %v", err)
+	}
+
+	if unrecorded.URL != "/api/1/networks.json" {
+		t.Errorf("Expected unrecorded /api/1/networks.json. Got %s", unrecorded.URL)
+	}
+
+	if len(transport.Unrecorded()) != 1 {
+		t.Errorf("Expected 1 unrecorded request. Got %v", transport.Unrecorded())
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	requests := 0
+
+	recorder := New(dir, Record)
+	recorder.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"data":[{"id":"NE_ASOS","name":"Nebraska ASOS"}]}`)),
+		}, nil
+	})
+
+	ctx := context.Background()
+	client := iem.NewClientWithOptions(recorder.Option())
+
+	if _, err := client.Networks().GetNetworks(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 1 {
+		t.Fatalf("Expected 1 recorded request. Got %d", requests)
+	}
+
+	client = iem.NewClientWithOptions(ForTest(t, dir))
+
+	networks, err := client.Networks().GetNetworks(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(networks) != 1 || networks[0].Id != "NE_ASOS" {
+		t.Errorf("Expected replayed NE_ASOS network. Got %v", networks)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected replay to not send requests. Got %d", requests)
+	}
+}
+
+func TestKey(t *testing.T) {
+	a := Key("https://mesonet.agron.iastate.edu/cgi-bin/request/asos.py?station=DSM&data=tmpf&station=AMW")
+	b := Key("http://127.0.0.1:8080/cgi-bin/request/asos.py?data=tmpf&station=AMW&station=DSM")
+
+	if a != b {
+		t.Errorf("Expected equal keys %s %s", a, b)
+	}
+}
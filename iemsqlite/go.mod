@@ -0,0 +1,12 @@
+module github.com/colevoss/go-iem-sdk/iemsqlite
+
+go 1.21.1
+
+require (
+	github.com/colevoss/go-iem-sdk v0.0.0-20261019055611-ce782d7adc42
+	github.com/mattn/go-sqlite3 v1.14.22
+)
+
+// Builds against the SDK in this repository. Modules depending on this one
+// ignore the replace and use the required version of the SDK
+replace github.com/colevoss/go-iem-sdk => ../
@@ -0,0 +1,136 @@
+package iemsqlite
+
+// Migrations are applied in order and the number applied is stored as the
+// database's user_version. Released migrations must never be edited. When a
+// column is added to IEMWeatherData, append a migration that adds it to
+// observations with ALTER TABLE
+var migrations = []string{
+	// 1: Initial schema
+	`CREATE TABLE networks (
+		id TEXT PRIMARY KEY,
+		name TEXT,
+		tzname TEXT,
+		windrose_update TEXT
+	);
+
+	CREATE TABLE stations (
+		id TEXT NOT NULL,
+		network TEXT NOT NULL,
+		name TEXT,
+		plot_name TEXT,
+		state TEXT,
+		country TEXT,
+		county TEXT,
+		elevation REAL,
+		latitude REAL,
+		longitude REAL,
+		tzname TEXT,
+		climate_site TEXT,
+		synop REAL,
+		online INTEGER,
+		params TEXT,
+		archive_begin TEXT,
+		PRIMARY KEY (id, network)
+	);
+
+	CREATE INDEX stations_network ON stations (network);
+
+	CREATE TABLE observations (
+		station TEXT NOT NULL,
+		valid TEXT NOT NULL,
+		lon REAL,
+		lat REAL,
+		elevation TEXT,
+		tmpf REAL,
+		tmpc REAL,
+		dwpf REAL,
+		dwpc REAL,
+		relh REAL,
+		feel REAL,
+		drct REAL,
+		sknt REAL,
+		sped REAL,
+		alti REAL,
+		mslp REAL,
+		p01m REAL,
+		p01i REAL,
+		vsby REAL,
+		gust REAL,
+		gust_mph REAL,
+		skyc1 TEXT,
+		skyc2 TEXT,
+		skyc3 TEXT,
+		skyl1 REAL,
+		skyl2 REAL,
+		skyl3 REAL,
+		wxcodes TEXT,
+		ice_accretion_1hr REAL,
+		ice_accretion_3hr REAL,
+		ice_accretion_6hr REAL,
+		peak_wind_gust REAL,
+		peak_wind_gust_mph REAL,
+		peak_wind_drct REAL,
+		peak_wind_time TEXT,
+		snowdepth REAL,
+		metar TEXT,
+		PRIMARY KEY (station, valid)
+	);
+
+	CREATE INDEX observations_valid ON observations (valid);`,
+
+	// 2: Trace flags. A trace is stored as 0 in its column and 1 in the
+	// column's _trace flag. Version 1 stored traces as asos.py's 0.0001
+	`ALTER TABLE observations ADD COLUMN tmpf_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN tmpc_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN dwpf_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN dwpc_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN relh_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN feel_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN drct_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN sknt_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN sped_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN alti_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN mslp_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN p01m_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN p01i_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN vsby_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN gust_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN gust_mph_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN skyl1_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN skyl2_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN skyl3_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN ice_accretion_1hr_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN ice_accretion_3hr_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN ice_accretion_6hr_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN peak_wind_gust_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN peak_wind_gust_mph_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN peak_wind_drct_trace INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE observations ADD COLUMN snowdepth_trace INTEGER NOT NULL DEFAULT 0;
+
+	UPDATE observations SET tmpf = 0, tmpf_trace = 1 WHERE tmpf = 0.0001;
+	UPDATE observations SET tmpc = 0, tmpc_trace = 1 WHERE tmpc = 0.0001;
+	UPDATE observations SET dwpf = 0, dwpf_trace = 1 WHERE dwpf = 0.0001;
+	UPDATE observations SET dwpc = 0, dwpc_trace = 1 WHERE dwpc = 0.0001;
+	UPDATE observations SET relh = 0, relh_trace = 1 WHERE relh = 0.0001;
+	UPDATE observations SET feel = 0, feel_trace = 1 WHERE feel = 0.0001;
+	UPDATE observations SET drct = 0, drct_trace = 1 WHERE drct = 0.0001;
+	UPDATE observations SET sknt = 0, sknt_trace = 1 WHERE sknt = 0.0001;
+	UPDATE observations SET sped = 0, sped_trace = 1 WHERE sped = 0.0001;
+	UPDATE observations SET alti = 0, alti_trace = 1 WHERE alti = 0.0001;
+	UPDATE observations SET mslp = 0, mslp_trace = 1 WHERE mslp = 0.0001;
+	UPDATE observations SET p01m = 0, p01m_trace = 1 WHERE p01m = 0.0001;
+	UPDATE observations SET p01i = 0, p01i_trace = 1 WHERE p01i = 0.0001;
+	UPDATE observations SET vsby = 0, vsby_trace = 1 WHERE vsby = 0.0001;
+	UPDATE observations SET gust = 0, gust_trace = 1 WHERE gust = 0.0001;
+	UPDATE observations SET gust_mph = 0, gust_mph_trace = 1 WHERE gust_mph = 0.0001;
+	UPDATE observations SET skyl1 = 0, skyl1_trace = 1 WHERE skyl1 = 0.0001;
+	UPDATE observations SET skyl2 = 0, skyl2_trace = 1 WHERE skyl2 = 0.0001;
+	UPDATE observations SET skyl3 = 0, skyl3_trace = 1 WHERE skyl3 = 0.0001;
+	UPDATE observations SET ice_accretion_1hr = 0, ice_accretion_1hr_trace = 1 WHERE ice_accretion_1hr = 0.0001;
+	UPDATE observations SET ice_accretion_3hr = 0, ice_accretion_3hr_trace = 1 WHERE ice_accretion_3hr = 0.0001;
+	UPDATE observations SET ice_accretion_6hr = 0, ice_accretion_6hr_trace = 1 WHERE ice_accretion_6hr = 0.0001;
+	UPDATE observations SET peak_wind_gust = 0, peak_wind_gust_trace = 1 WHERE peak_wind_gust = 0.0001;
+	UPDATE observations SET peak_wind_gust_mph = 0, peak_wind_gust_mph_trace = 1 WHERE peak_wind_gust_mph = 0.0001;
+	UPDATE observations SET peak_wind_drct = 0, peak_wind_drct_trace = 1 WHERE peak_wind_drct = 0.0001;
+	UPDATE observations SET snowdepth = 0, snowdepth_trace = 1 WHERE snowdepth = 0.0001;`,
+}
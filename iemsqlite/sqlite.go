@@ -0,0 +1,306 @@
+// Package iemsqlite stores networks, stations and weather observations in a
+// SQLite database. It works with any database/sql SQLite driver, which the
+// caller registers and opens. It is a separate module so the SDK does not
+// depend on a SQLite driver
+//
+//	go get github.com/colevoss/go-iem-sdk/iemsqlite
+package iemsqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+// Layout of times stored in the database. Times are stored in UTC and sort as text
+const TimeLayout = "2006-01-02 15:04:05"
+
+// Observations are inserted in transactions of this many rows
+const batchSize = 5000
+
+// Sink inserts SDK types into a SQLite database
+type Sink struct {
+	db *sql.DB
+}
+
+// Open migrates a database to the latest schema and creates a Sink for it
+func Open(ctx context.Context, db *sql.DB) (*Sink, error) {
+	s := &Sink{db: db}
+
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Version of the database schema (number of migrations applied)
+func (s *Sink) Version(ctx context.Context) (int, error) {
+	var version int
+
+	err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+
+	return version, err
+}
+
+// Migrate applies the migrations a database has not had applied yet
+func (s *Sink) Migrate(ctx context.Context) error {
+	version, err := s.Version(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		err := s.transaction(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+				return fmt.Errorf("migration %d [%w]", i+1, err)
+			}
+
+			// PRAGMA does not accept parameters
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1))
+
+			return err
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Sink) transaction(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func formatTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(TimeLayout)
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// Builds an insert statement that updates the row when its key already exists
+func upsertSQL(table string, columns []string, key []string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	updates := []string{}
+
+	for _, c := range columns {
+		updates = append(updates, fmt.Sprintf("%s = excluded.%s", c, c))
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s",
+		table,
+		strings.Join(columns, ", "),
+		placeholders,
+		strings.Join(key, ", "),
+		strings.Join(updates, ", "),
+	)
+}
+
+// Runs a prepared statement for each of n rows in a transaction
+func (s *Sink) upsert(ctx context.Context, query string, n int, args func(i int) []interface{}) error {
+	return s.transaction(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+
+		if err != nil {
+			return err
+		}
+
+		defer stmt.Close()
+
+		for i := 0; i < n; i++ {
+			if _, err := stmt.ExecContext(ctx, args(i)...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// InsertNetworks inserts or updates networks
+func (s *Sink) InsertNetworks(ctx context.Context, networks []*iem.Network) error {
+	query := upsertSQL("networks", []string{"id", "name", "tzname", "windrose_update"}, []string{"id"})
+
+	return s.upsert(ctx, query, len(networks), func(i int) []interface{} {
+		n := networks[i]
+		return []interface{}{n.Id, nullString(n.Name), nullString(n.Tz), formatTime(&n.WindroseUpdate)}
+	})
+}
+
+var stationColumns = []string{
+	"id", "network", "name", "plot_name", "state", "country", "county", "elevation", "latitude",
+	"longitude", "tzname", "climate_site", "synop", "online", "params", "archive_begin",
+}
+
+// InsertStations inserts or updates stations
+func (s *Sink) InsertStations(ctx context.Context, stations []*iem.Station) error {
+	query := upsertSQL("stations", stationColumns, []string{"id", "network"})
+
+	return s.upsert(ctx, query, len(stations), func(i int) []interface{} {
+		st := stations[i]
+
+		return []interface{}{
+			st.Id, st.Network, nullString(st.Name), nullString(st.PlotName), nullString(st.State),
+			nullString(st.Country), nullString(st.County), st.Elevation, st.Latitude, st.Longitude,
+			nullString(st.Timezone), nullString(st.ClimateSite), st.Synop, st.Online,
+			nullString(st.Params), formatTime(&st.ArchiveBegins),
+		}
+	})
+}
+
+// Numeric data columns have a _trace flag column that is 1 when the value is a
+// trace amount, stored as 0
+func isNumeric(c iem.WeatherDataData) bool {
+	_, ok := (&iem.IEMWeatherData{}).Float(c)
+	return ok
+}
+
+func traceFlag(d *iem.IEMWeatherData, c iem.WeatherDataData) int {
+	if d.IsTrace(c) {
+		return 1
+	}
+
+	return 0
+}
+
+func observationArgs(d *iem.IEMWeatherData, metadata []string, columns []iem.WeatherDataData) []interface{} {
+	args := []interface{}{d.Station, formatTime(d.Time)}
+
+	for _, m := range metadata {
+		switch m {
+		case "lon", "lat":
+			c := iem.WeatherDataData(m)
+
+			if d.IsMissing(c) {
+				args = append(args, nil)
+			} else {
+				value, _ := d.Float(c)
+				args = append(args, value)
+			}
+		case "elevation":
+			args = append(args, nullString(d.Elevation))
+		}
+	}
+
+	for _, c := range columns {
+		if d.IsMissing(c) {
+			args = append(args, nil)
+			continue
+		}
+
+		if c == iem.PeakWindTime {
+			args = append(args, formatTime(d.PeakWindTime))
+			continue
+		}
+
+		if text, ok := d.Text(c); ok {
+			args = append(args, text)
+			continue
+		}
+
+		value, _ := d.Float(c)
+		args = append(args, value)
+	}
+
+	for _, c := range columns {
+		if isNumeric(c) {
+			args = append(args, traceFlag(d, c))
+		}
+	}
+
+	return args
+}
+
+// InsertObservations inserts observations of a query in transactions of up to
+// 5000 rows. Only the columns the query requested are written, so columns of
+// observations already stored for a station and time that the query did not
+// request keep their values. Missing values are stored as NULL and trace
+// amounts as 0 with the column's _trace flag set to 1
+func (s *Sink) InsertObservations(ctx context.Context, query *iem.WeatherDataQueryBuilder, data []*iem.IEMWeatherData) error {
+	metadata := query.MetadataColumns()
+	dataColumns := query.Columns()
+	columns := append([]string{"station", "valid"}, metadata...)
+
+	for _, c := range dataColumns {
+		columns = append(columns, string(c))
+	}
+
+	for _, c := range dataColumns {
+		if isNumeric(c) {
+			columns = append(columns, string(c)+"_trace")
+		}
+	}
+
+	statement := upsertSQL("observations", columns, []string{"station", "valid"})
+
+	rows := make([]*iem.IEMWeatherData, 0, len(data))
+
+	for _, d := range data {
+		if d.Time != nil {
+			rows = append(rows, d)
+		}
+	}
+
+	for start := 0; start < len(rows); start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+
+		err := s.upsert(ctx, statement, len(batch), func(i int) []interface{} {
+			return observationArgs(batch[i], metadata, dataColumns)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Load gets the weather data of a query and inserts it. Returns the number of
+// observations inserted
+func (s *Sink) Load(ctx context.Context, weather iem.WeatherService, query *iem.WeatherDataQueryBuilder) (int, error) {
+	data, err := weather.Get(ctx, query)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.InsertObservations(ctx, query, data); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
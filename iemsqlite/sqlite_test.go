@@ -0,0 +1,354 @@
+package iemsqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+	"github.com/colevoss/go-iem-sdk/iemmock"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Observation data columns in asos.py's order
+func observationColumns() []iem.WeatherDataData {
+	return iem.NewWeatherDataQuery().Data(iem.All).Columns()
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "iem.db"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	sink, err := Open(ctx, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := sink.Version(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != len(migrations) {
+		t.Errorf("Expected version %d. Got %d", len(migrations), version)
+	}
+
+	// Opening a migrated database again does not reapply migrations
+	if _, err := Open(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every IEMWeatherData column must have been added by a migration
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info('observations')")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rows.Close()
+
+	columns := map[string]bool{}
+
+	for rows.Next() {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+
+		columns[name] = true
+	}
+
+	for _, c := range observationColumns() {
+		if !columns[string(c)] {
+			t.Errorf("Expected observations column %s. Add a migration for it", c)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "PRAGMA user_version = 1000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(ctx, db); err == nil {
+		t.Error("Expected error opening newer database")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file, err := os.Open("../data/full_weather_data.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := iem.ParseWeatherData(file, iem.NewWeatherDataQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	sink, err := Open(ctx, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weather := iemmock.NewWeatherService()
+	weather.Return(data...)
+	query := iem.NewWeatherDataQuery().Stations("LNK").Data(iem.All).LatLon(true).Elevation(true)
+
+	// Loading twice replaces the rows instead of duplicating them
+	for i := 0; i < 2; i++ {
+		n, err := sink.Load(ctx, weather, query)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != len(data) {
+			t.Errorf("Expected %d loaded. Got %d", len(data), n)
+		}
+	}
+
+	weather.AssertCalled(t, 2)
+
+	var count int
+
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM observations").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != len(data) {
+		t.Errorf("Expected %d observations. Got %d", len(data), count)
+	}
+
+	first := data[0]
+	var tmpf float64
+	var valid string
+	var gust sql.NullFloat64
+
+	err = db.QueryRowContext(
+		ctx,
+		"SELECT valid, tmpf, gust FROM observations WHERE station = ? ORDER BY valid LIMIT 1",
+		first.Station,
+	).Scan(&valid, &tmpf, &gust)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := first.Time.UTC().Format(TimeLayout); valid != expected {
+		t.Errorf("Expected valid %s. Got %s", expected, valid)
+	}
+
+	if tmpf != first.TemperatureF {
+		t.Errorf("Expected tmpf %f. Got %f", first.TemperatureF, tmpf)
+	}
+
+	if gust.Valid != (first.WindGustKnots != 0) {
+		t.Errorf("Expected gust valid %t. Got %t", first.WindGustKnots != 0, gust.Valid)
+	}
+}
+
+func TestInsertStations(t *testing.T) {
+	ctx := context.Background()
+	sink, err := Open(ctx, openTestDB(t))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	networks := []*iem.Network{{Id: "NE_ASOS", Name: "Nebraska ASOS"}}
+	stations := []*iem.Station{{Id: "LNK", Network: "NE_ASOS", Name: "Lincoln"}}
+
+	for i := 0; i < 2; i++ {
+		if err := sink.InsertNetworks(ctx, networks); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := sink.InsertStations(ctx, stations); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var name string
+
+	if err := sink.db.QueryRowContext(ctx, "SELECT name FROM stations WHERE id = 'LNK'").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "Lincoln" {
+		t.Errorf("Expected Lincoln. Got %s", name)
+	}
+}
+
+// Reads every column of the stored observations as text
+func dumpObservations(t *testing.T, db *sql.DB) [][]string {
+	t.Helper()
+
+	rows, err := db.Query("SELECT * FROM observations ORDER BY station, valid")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rows.Close()
+
+	columns, _ := rows.Columns()
+	dump := [][]string{}
+
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			t.Fatal(err)
+		}
+
+		row := make([]string, len(columns))
+
+		for i, v := range values {
+			row[i] = columns[i] + "=NULL"
+
+			if v.Valid {
+				row[i] = columns[i] + "=" + v.String
+			}
+		}
+
+		dump = append(dump, row)
+	}
+
+	return dump
+}
+
+func TestPartialLoad(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	sink, err := Open(ctx, db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := iem.NewWeatherDataQuery().Stations("LNK").Data(iem.All).LatLon(true).Elevation(true)
+	fixture, err := os.ReadFile("../data/full_weather_data.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := iem.ParseWeatherData(bytes.NewReader(fixture), all)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.InsertObservations(ctx, all, data); err != nil {
+		t.Fatal(err)
+	}
+
+	before := dumpObservations(t, db)
+
+	// A second load of only tmpf changes tmpf and nothing else
+	tmpf := iem.NewWeatherDataQuery().Stations("LNK").Data(iem.TempF)
+	data, err = iem.ParseWeatherData(bytes.NewReader(fixture), tmpf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range data {
+		d.SetFloat(iem.TempF, d.TemperatureF+1)
+	}
+
+	if err := sink.InsertObservations(ctx, tmpf, data); err != nil {
+		t.Fatal(err)
+	}
+
+	after := dumpObservations(t, db)
+
+	if len(before) != len(after) {
+		t.Fatalf("Expected %d observations. Got %d", len(before), len(after))
+	}
+
+	for i := range before {
+		for j := range before[i] {
+			changed := before[i][j] != after[i][j]
+
+			if strings.HasPrefix(before[i][j], "tmpf=") != changed {
+				t.Errorf("Expected only tmpf to change. Got %s -> %s", before[i][j], after[i][j])
+			}
+		}
+	}
+
+	// The second observation has a trace of precipitation and missing pressure
+	if before[1][17] != "p01i=0" || before[1][15] != "mslp=NULL" || before[1][16] != "p01m=0" {
+		t.Errorf("Expected trace and missing values. Got %v", before[1][15:18])
+	}
+
+	if !hasValue(before[1], "p01i_trace=1") || !hasValue(before[1], "p01m_trace=1") || !hasValue(before[1], "tmpf_trace=0") {
+		t.Errorf("Expected trace flags. Got %v", before[1])
+	}
+}
+
+func hasValue(row []string, value string) bool {
+	for _, v := range row {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestMigrateTraces(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	// A version 1 database stored traces as 0.0001
+	if _, err := db.ExecContext(ctx, migrations[0]+"; PRAGMA user_version = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.ExecContext(ctx, "INSERT INTO observations (station, valid, p01i, tmpf) VALUES ('LNK', '2023-10-04 01:10:00', 0.0001, 0)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	row := dumpObservations(t, db)[0]
+
+	if !hasValue(row, "p01i=0") || !hasValue(row, "p01i_trace=1") || !hasValue(row, "tmpf=0") || !hasValue(row, "tmpf_trace=0") {
+		t.Errorf("Expected the trace to be migrated. Got %v", row)
+	}
+}
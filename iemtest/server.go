@@ -0,0 +1,397 @@
+// Package iemtest provides an in memory IEM server for tests of code that uses
+// an iem.Client. It serves networks, stations and ASOS observations seeded by
+// the test and can inject errors and latency.
+//
+//	server := iemtest.NewServer()
+//	defer server.Close()
+//
+//	server.AddStations(&iem.Station{Id: "LNK", Network: "NE_ASOS"})
+//	client := server.Client()
+package iemtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+const (
+	networksPath = "/api/1/networks.json"
+	networkPath  = "/api/1/network/"
+	stationPath  = "/api/1/station/"
+	asosPath     = "/cgi-bin/request/asos.py"
+)
+
+type injectedError struct {
+	status int
+	n      int // Remaining responses to fail. Negative fails every response
+}
+
+// Server is an httptest.Server that serves the IEM API from seeded data
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	networks     []*iem.Network
+	stations     []*iem.Station
+	observations []*iem.IEMWeatherData
+	errors       map[string]*injectedError
+	latency      time.Duration
+	requests     []*url.URL
+}
+
+// NewServer creates and starts a Server. It must be closed
+func NewServer() *Server {
+	s := &Server{errors: map[string]*injectedError{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(networksPath, s.handleNetworks)
+	mux.HandleFunc(networkPath, s.handleNetwork)
+	mux.HandleFunc(stationPath, s.handleStation)
+	mux.HandleFunc(asosPath, s.handleASOS)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+
+	return s
+}
+
+// Client creates a client that sends requests to the server
+func (s *Server) Client(opts ...iem.ClientOption) *iem.Client {
+	opts = append([]iem.ClientOption{
+		iem.WithBaseUrl(s.URL),
+		iem.WithHTTPClient(s.Server.Client()),
+	}, opts...)
+
+	return iem.NewClientWithOptions(opts...)
+}
+
+// AddNetworks seeds networks
+func (s *Server) AddNetworks(networks ...*iem.Network) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.networks = append(s.networks, networks...)
+}
+
+// AddStations seeds stations
+func (s *Server) AddStations(stations ...*iem.Station) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stations = append(s.stations, stations...)
+}
+
+// AddObservations seeds observations. Columns are served as missing or trace
+// amounts as reported by IEMWeatherData's IsMissing and IsTrace, so mark real
+// zeros with SetFloat and traces with SetTrace
+func (s *Server) AddObservations(data ...*iem.IEMWeatherData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.observations = append(s.observations, data...)
+}
+
+// AddObservationsCSV seeds observations from asos.py CSV data with times in
+// UTC, missing values as M and traces as T, such as data/full_weather_data.csv
+func (s *Server) AddObservationsCSV(reader io.Reader) error {
+	data, err := iem.ParseWeatherData(reader, iem.NewWeatherDataQuery())
+
+	if err != nil {
+		return err
+	}
+
+	s.AddObservations(data...)
+
+	return nil
+}
+
+// InjectError responds to the next n requests to a path (such as
+// /api/1/networks.json) with a status. Negative n fails every request until
+// the error is cleared with an n of 0
+func (s *Server) InjectError(path string, status int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n == 0 {
+		delete(s.errors, path)
+		return
+	}
+
+	s.errors[path] = &injectedError{status: status, n: n}
+}
+
+// SetLatency delays every response
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// Requests the server has received
+func (s *Server) Requests() []*url.URL {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*url.URL{}, s.requests...)
+}
+
+// Records requests, applies latency and responds with injected errors
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL)
+		latency := s.latency
+		status := 0
+
+		if e, ok := s.errors[r.URL.Path]; ok {
+			status = e.status
+
+			if e.n > 0 {
+				e.n--
+
+				if e.n == 0 {
+					delete(s.errors, r.URL.Path)
+				}
+			}
+		}
+
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if status != 0 {
+			writeError(w, status, http.StatusText(status))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Writes an error in the format of the IEM API
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"detail": detail})
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// Id of a /api/1/<kind>/<id>.json path
+func pathId(path string, prefix string) (string, bool) {
+	id := strings.TrimPrefix(path, prefix)
+
+	if !strings.HasSuffix(id, ".json") {
+		return "", false
+	}
+
+	id = strings.TrimSuffix(id, ".json")
+
+	return id, id != "" && !strings.Contains(id, "/")
+}
+
+func (s *Server) handleNetworks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	networks := append([]*iem.Network{}, s.networks...)
+	s.mu.Unlock()
+
+	writeData(w, networks)
+}
+
+func (s *Server) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathId(r.URL.Path, networkPath)
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stations := []*iem.Station{}
+
+	for _, station := range s.stations {
+		if station.Network == id {
+			stations = append(stations, station)
+		}
+	}
+
+	found := len(stations) > 0
+
+	for _, network := range s.networks {
+		found = found || network.Id == id
+	}
+
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Network %s not found", id))
+		return
+	}
+
+	writeData(w, stations)
+}
+
+func (s *Server) handleStation(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathId(r.URL.Path, stationPath)
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stations := []*iem.Station{}
+
+	for _, station := range s.stations {
+		if station.Id == id {
+			stations = append(stations, station)
+		}
+	}
+
+	if len(stations) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Station %s not found", id))
+		return
+	}
+
+	writeData(w, stations)
+}
+
+// Builds the weather query of asos.py url values
+func parseWeatherQuery(v url.Values) (*iem.WeatherDataQueryBuilder, time.Time, time.Time, error) {
+	query := iem.NewWeatherDataQuery()
+
+	if len(v["station"]) == 0 || len(v["data"]) == 0 {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("station and data are required")
+	}
+
+	query.Stations(v["station"]...)
+
+	for _, d := range v["data"] {
+		query.Data(iem.WeatherDataData(d))
+	}
+
+	if tz := v.Get("tz"); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return nil, time.Time{}, time.Time{}, fmt.Errorf("unknown tz %s", tz)
+		}
+
+		query.Timezone(tz)
+	}
+
+	location := query.Location()
+
+	date := func(n string) (time.Time, error) {
+		parts := [3]int{}
+
+		for i, key := range []string{"year", "month", "day"} {
+			p, err := strconv.Atoi(v.Get(key + n))
+
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid %s%s", key, n)
+			}
+
+			parts[i] = p
+		}
+
+		return time.Date(parts[0], time.Month(parts[1]), parts[2], 0, 0, 0, 0, location), nil
+	}
+
+	start, err := date("1")
+
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	end, err := date("2")
+
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	query.Start(start).End(end)
+	query.LatLon(v.Get("latlon") == string(iem.Yes))
+	query.Elevation(v.Get("elev") == string(iem.Yes))
+
+	if missing := v.Get("missing"); missing != "" {
+		query.Missing(iem.WeatherDataQueryMissing(missing))
+	}
+
+	if trace := v.Get("trace"); trace != "" {
+		query.Trace(iem.WeatherDataQueryTrace(trace))
+	}
+
+	if format := v.Get("format"); format != "" {
+		query.Format(iem.WeatherDataQueryFormat(format))
+	}
+
+	return query, start, end, nil
+}
+
+// Serves the observations of the requested stations from the start day up to
+// but not including the end day, in the requested timezone, columns, format
+// and encodings. Report types are not filtered
+func (s *Server) handleASOS(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	query, start, end, err := parseWeatherQuery(v)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stations := map[string]bool{}
+
+	for _, station := range v["station"] {
+		stations[station] = true
+	}
+
+	s.mu.Lock()
+	data := []*iem.IEMWeatherData{}
+
+	for _, d := range s.observations {
+		t := d.Time
+
+		if !stations[d.Station] || t == nil || t.Before(start) || !t.Before(end) {
+			continue
+		}
+
+		data = append(data, d)
+	}
+
+	s.mu.Unlock()
+
+	sort.SliceStable(data, func(i, j int) bool {
+		a, b := data[i], data[j]
+
+		if a.Station != b.Station {
+			return a.Station < b.Station
+		}
+
+		return a.Time.Before(*b.Time)
+	})
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	iem.WriteWeatherData(w, data, query)
+}
@@ -0,0 +1,225 @@
+package iemtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+func seededServer(t *testing.T) *Server {
+	t.Helper()
+
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	file, err := os.Open("../data/full_weather_data.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	if err := server.AddObservationsCSV(file); err != nil {
+		t.Fatal(err)
+	}
+
+	server.AddNetworks(&iem.Network{Id: "NE_ASOS", Name: "Nebraska ASOS"})
+	server.AddStations(
+		&iem.Station{Id: "LNK", Network: "NE_ASOS", Name: "Lincoln"},
+		&iem.Station{Id: "OMA", Network: "NE_ASOS", Name: "Omaha"},
+	)
+
+	return server
+}
+
+func TestNetworksAndStations(t *testing.T) {
+	server := seededServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	networks, err := client.Networks().GetNetworks(ctx)
+
+	if err != nil || len(networks) != 1 || networks[0].Id != "NE_ASOS" {
+		t.Fatalf("Expected NE_ASOS network. Got %v %v", networks, err)
+	}
+
+	stations, err := client.Stations().GetStations(ctx, "NE_ASOS")
+
+	if err != nil || len(stations) != 2 {
+		t.Fatalf("Expected 2 stations. Got %v %v", stations, err)
+	}
+
+	station, err := client.Stations().GetStation(ctx, "OMA")
+
+	if err != nil || station.Name != "Omaha" {
+		t.Fatalf("Expected Omaha. Got %v %v", station, err)
+	}
+
+	var notFound iem.IEMNotFoundError
+
+	if _, err := client.Stations().GetStation(ctx, "XXX"); !errors.As(err, &notFound) {
+		t.Errorf("Expected IEMNotFoundError. Got %v", err)
+	}
+
+	if _, err := client.Stations().GetStations(ctx, "XX_ASOS"); !errors.As(err, &notFound) {
+		t.Errorf("Expected IEMNotFoundError. Got %v", err)
+	}
+}
+
+func TestWeatherQuery(t *testing.T) {
+	server := seededServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	// The end day is not included
+	query := iem.NewWeatherDataQuery().
+		Stations("LNK").
+		Data(iem.TempF, iem.PrecipInch).
+		Start(time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)).
+		End(time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC))
+
+	data, err := client.Weather().Get(ctx, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 0 {
+		t.Errorf("Expected no observations before the end day. Got %d", len(data))
+	}
+
+	query.End(time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC))
+
+	data, err = client.Weather().Get(ctx, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 35 {
+		t.Fatalf("Expected 35 observations. Got %d", len(data))
+	}
+
+	if data[0].TemperatureF != 79 || data[0].DewPointF != 0 {
+		t.Errorf("Expected only requested columns. Got %+v", data[0])
+	}
+
+	// The first 5 hours in Chicago are on the 3rd
+	query.Timezone("America/Chicago")
+
+	data, err = client.Weather().Get(ctx, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) == 0 || data[0].Time.Location().String() != "America/Chicago" || data[0].Time.Day() != 4 {
+		t.Errorf("Expected observations from the 4th in America/Chicago. Got %v", data)
+	}
+
+	if _, err := client.Weather().Get(ctx, query.Stations("OMA")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWeatherEncodings(t *testing.T) {
+	server := seededServer(t)
+
+	get := func(values string) string {
+		t.Helper()
+
+		resp, err := http.Get(server.URL + "/cgi-bin/request/asos.py?station=LNK&data=p01i&data=mslp" +
+			"&year1=2023&month1=10&day1=4&year2=2023&month2=10&day2=5&tz=Etc%2FUTC" + values)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		lines := strings.Split(string(body), "\n")
+
+		// The second observation has a trace of precipitation and missing pressure
+		return lines[2]
+	}
+
+	if line := get("&missing=M&trace=T"); line != "LNK,2023-10-04 01:10,M,T" {
+		t.Errorf("Expected M and T. Got %s", line)
+	}
+
+	if line := get("&missing=null&trace=0.0001"); line != "LNK,2023-10-04 01:10,null,0.0001" {
+		t.Errorf("Expected null and 0.0001. Got %s", line)
+	}
+
+	if line := get("&missing=empty&trace=empty"); line != "LNK,2023-10-04 01:10,," {
+		t.Errorf("Expected empty values. Got %s", line)
+	}
+
+	if line := get("&missing=M&trace=T&format=onlytdf"); line != "LNK\t2023-10-04 01:10\tM\tT" {
+		t.Errorf("Expected tab delimited values. Got %s", line)
+	}
+}
+
+func TestInjectErrorAndLatency(t *testing.T) {
+	server := seededServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	server.InjectError("/api/1/station/LNK.json", http.StatusNotFound, 1)
+
+	if _, err := client.Stations().GetStation(ctx, "LNK"); err == nil {
+		t.Error("Expected injected error")
+	}
+
+	if _, err := client.Stations().GetStation(ctx, "LNK"); err != nil {
+		t.Errorf("Expected injected error to be used once. Got %v", err)
+	}
+
+	server.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Networks().GetNetworks(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded. Got %v", err)
+	}
+
+	if len(server.Requests()) != 3 {
+		t.Errorf("Expected 3 requests. Got %d", len(server.Requests()))
+	}
+}
+
+func TestAddObservationsTraceAndZero(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	valid := time.Date(2023, 1, 4, 1, 10, 0, 0, time.UTC)
+	d := &iem.IEMWeatherData{Station: "LNK", Time: &valid}
+	d.SetFloat(iem.TempF, 0)
+	d.SetTrace(iem.PrecipInch)
+	server.AddObservations(d)
+
+	resp, err := http.Get(server.URL + "/cgi-bin/request/asos.py?station=LNK&data=tmpf&data=dwpf&data=p01i" +
+		"&year1=2023&month1=1&day1=4&year2=2023&month2=1&day2=5&tz=Etc%2FUTC&missing=M&trace=T")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if string(body) != "station,valid,tmpf,dwpf,p01i\nLNK,2023-01-04 01:10,0.00,M,T\n" {
+		t.Errorf("Expected observed 0, missing dew point and trace. Got %q", body)
+	}
+}
@@ -0,0 +1,404 @@
+package iem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ISUSMMode string
+
+const (
+	ISUSMHourly ISUSMMode = "hourly"
+	ISUSMDaily  ISUSMMode = "daily"
+)
+
+type ISUSMVariable string
+
+const (
+	// Hourly and daily
+	ISUSMSolar              ISUSMVariable = "solar"     // Solar Radiation [W/m2 hourly, MJ/m2 daily]
+	ISUSMPrecip             ISUSMVariable = "precip"    // Precipitation [inch]
+	ISUSMWindSpeedMPH       ISUSMVariable = "speed"     // Wind Speed [mph]
+	ISUSMWindGustMPH        ISUSMVariable = "gust"      // Wind Gust [mph]
+	ISUSMEvapotranspiration ISUSMVariable = "et"        // Potential Evapotranspiration [inch]
+	ISUSMSoilTemp4In        ISUSMVariable = "soil04t"   // 4 inch Soil Temperature [F]
+	ISUSMSoilTemp12In       ISUSMVariable = "soil12t"   // 12 inch Soil Temperature [F]
+	ISUSMSoilTemp24In       ISUSMVariable = "soil24t"   // 24 inch Soil Temperature [F]
+	ISUSMSoilTemp50In       ISUSMVariable = "soil50t"   // 50 inch Soil Temperature [F]
+	ISUSMSoilMoisture12In   ISUSMVariable = "soil12vwc" // 12 inch Volumetric Soil Moisture [%]
+	ISUSMSoilMoisture24In   ISUSMVariable = "soil24vwc" // 24 inch Volumetric Soil Moisture [%]
+	ISUSMSoilMoisture50In   ISUSMVariable = "soil50vwc" // 50 inch Volumetric Soil Moisture [%]
+
+	// Hourly
+	ISUSMTempF            ISUSMVariable = "tmpf" // Air Temperature [F]
+	ISUSMRelativeHumidity ISUSMVariable = "relh" // Relative Humidity [%]
+	ISUSMWindDirection    ISUSMVariable = "drct" // Wind Direction [deg]
+
+	// Daily
+	ISUSMHighF               ISUSMVariable = "high"     // High Air Temperature [F]
+	ISUSMLowF                ISUSMVariable = "low"      // Low Air Temperature [F]
+	ISUSMMinRelativeHumidity ISUSMVariable = "rh_min"   // Minimum Relative Humidity [%]
+	ISUSMRelativeHumidityAvg ISUSMVariable = "rh"       // Average Relative Humidity [%]
+	ISUSMMaxRelativeHumidity ISUSMVariable = "rh_max"   // Maximum Relative Humidity [%]
+	ISUSMSoilTemp4InLow      ISUSMVariable = "soil04tn" // 4 inch Soil Temperature Low [F]
+	ISUSMSoilTemp4InHigh     ISUSMVariable = "soil04tx" // 4 inch Soil Temperature High [F]
+)
+
+// ISUSMData represents an hourly or daily reading at an ISU Soil Moisture station
+// Properties are present depending on the query used to fetch the data
+type ISUSMData struct {
+	Station string `json:"station"` // Station recorded at (station)
+
+	Time *time.Time `json:"time"` // Time recorded at, or the date of daily data (valid)
+
+	TemperatureF        float64 `json:"tmpf,omitempty"`   // Air Temperature [F] (tmpf)
+	HighF               float64 `json:"high,omitempty"`   // High Air Temperature [F] (high)
+	LowF                float64 `json:"low,omitempty"`    // Low Air Temperature [F] (low)
+	RelativeHumidity    float64 `json:"relh,omitempty"`   // Relative Humidity [%] (relh, rh)
+	MinRelativeHumidity float64 `json:"rh_min,omitempty"` // Minimum Relative Humidity [%] (rh_min)
+	MaxRelativeHumidity float64 `json:"rh_max,omitempty"` // Maximum Relative Humidity [%] (rh_max)
+
+	SolarRadiation float64 `json:"solar,omitempty"` // Solar Radiation [W/m2 hourly, MJ/m2 daily] (solar)
+
+	Precip             float64 `json:"precip,omitempty"` // Precipitation [inch] (precip)
+	Evapotranspiration float64 `json:"et,omitempty"`     // Potential Evapotranspiration [inch] (et)
+
+	WindDirection float64 `json:"drct,omitempty"`  // Wind Direction [deg] (drct)
+	WindSpeedMPH  float64 `json:"speed,omitempty"` // Wind Speed [mph] (speed)
+	WindGustMPH   float64 `json:"gust,omitempty"`  // Wind Gust [mph] (gust)
+
+	SoilTemperature4InF     float64 `json:"soil04t,omitempty"`  // 4 inch Soil Temperature [F] (soil04t)
+	SoilTemperature4InLowF  float64 `json:"soil04tn,omitempty"` // 4 inch Soil Temperature Low [F] (soil04tn)
+	SoilTemperature4InHighF float64 `json:"soil04tx,omitempty"` // 4 inch Soil Temperature High [F] (soil04tx)
+	SoilTemperature12InF    float64 `json:"soil12t,omitempty"`  // 12 inch Soil Temperature [F] (soil12t)
+	SoilTemperature24InF    float64 `json:"soil24t,omitempty"`  // 24 inch Soil Temperature [F] (soil24t)
+	SoilTemperature50InF    float64 `json:"soil50t,omitempty"`  // 50 inch Soil Temperature [F] (soil50t)
+
+	SoilMoisture12In float64 `json:"soil12vwc,omitempty"` // 12 inch Volumetric Soil Moisture [%] (soil12vwc)
+	SoilMoisture24In float64 `json:"soil24vwc,omitempty"` // 24 inch Volumetric Soil Moisture [%] (soil24vwc)
+	SoilMoisture50In float64 `json:"soil50vwc,omitempty"` // 50 inch Volumetric Soil Moisture [%] (soil50vwc)
+
+	// Variables that were observed or missing. Bits are the variables'
+	// indexes in isusmVariables
+	state columnState
+}
+
+// Variables parsed into ISUSMData
+var isusmVariables = []ISUSMVariable{
+	ISUSMTempF, ISUSMHighF, ISUSMLowF, ISUSMRelativeHumidity, ISUSMRelativeHumidityAvg,
+	ISUSMMinRelativeHumidity, ISUSMMaxRelativeHumidity, ISUSMSolar, ISUSMPrecip,
+	ISUSMEvapotranspiration, ISUSMWindDirection, ISUSMWindSpeedMPH, ISUSMWindGustMPH,
+	ISUSMSoilTemp4In, ISUSMSoilTemp4InLow, ISUSMSoilTemp4InHigh, ISUSMSoilTemp12In,
+	ISUSMSoilTemp24In, ISUSMSoilTemp50In, ISUSMSoilMoisture12In, ISUSMSoilMoisture24In,
+	ISUSMSoilMoisture50In,
+}
+
+// Variables where 0 is a routinely observed value
+var isusmZeroObserved = map[ISUSMVariable]bool{
+	ISUSMSolar:              true,
+	ISUSMPrecip:             true,
+	ISUSMEvapotranspiration: true,
+	ISUSMWindDirection:      true,
+	ISUSMWindSpeedMPH:       true,
+	ISUSMWindGustMPH:        true,
+}
+
+func (d *ISUSMData) field(variable ISUSMVariable) *float64 {
+	switch variable {
+	case ISUSMTempF:
+		return &d.TemperatureF
+	case ISUSMHighF:
+		return &d.HighF
+	case ISUSMLowF:
+		return &d.LowF
+	case ISUSMRelativeHumidity, ISUSMRelativeHumidityAvg:
+		return &d.RelativeHumidity
+	case ISUSMMinRelativeHumidity:
+		return &d.MinRelativeHumidity
+	case ISUSMMaxRelativeHumidity:
+		return &d.MaxRelativeHumidity
+	case ISUSMSolar:
+		return &d.SolarRadiation
+	case ISUSMPrecip:
+		return &d.Precip
+	case ISUSMEvapotranspiration:
+		return &d.Evapotranspiration
+	case ISUSMWindDirection:
+		return &d.WindDirection
+	case ISUSMWindSpeedMPH:
+		return &d.WindSpeedMPH
+	case ISUSMWindGustMPH:
+		return &d.WindGustMPH
+	case ISUSMSoilTemp4In:
+		return &d.SoilTemperature4InF
+	case ISUSMSoilTemp4InLow:
+		return &d.SoilTemperature4InLowF
+	case ISUSMSoilTemp4InHigh:
+		return &d.SoilTemperature4InHighF
+	case ISUSMSoilTemp12In:
+		return &d.SoilTemperature12InF
+	case ISUSMSoilTemp24In:
+		return &d.SoilTemperature24InF
+	case ISUSMSoilTemp50In:
+		return &d.SoilTemperature50InF
+	case ISUSMSoilMoisture12In:
+		return &d.SoilMoisture12In
+	case ISUSMSoilMoisture24In:
+		return &d.SoilMoisture24In
+	case ISUSMSoilMoisture50In:
+		return &d.SoilMoisture50In
+	}
+
+	return nil
+}
+
+// IsMissing reports whether a variable is missing. Parsed data knows which of
+// its variables were missing or not in the response. Otherwise, as for data
+// created by hand, zero values are missing except for variables that are
+// routinely 0 (solar, precip, et and wind)
+func (d *ISUSMData) IsMissing(variable ISUSMVariable) bool {
+	if i, ok := variableIndex(isusmVariables, variable); ok {
+		if missing, known := d.state.isMissing(i); known {
+			return missing
+		}
+	}
+
+	field := d.field(variable)
+
+	if field == nil {
+		return true
+	}
+
+	return *field == 0 && !isusmZeroObserved[variable]
+}
+
+const defaultISUSMMode = ISUSMHourly
+
+// ISUSMQueryBuilder represents the url query sent to the IEM API
+// when requesting ISU Soil Moisture network data for stations
+type ISUSMQueryBuilder struct {
+	// Stations to get data from (AEEI4)
+	stations []string
+
+	// List of variables requested from IEM API
+	data []ISUSMVariable
+
+	// Start date to query for
+	start time.Time
+
+	// End date to query for
+	end time.Time
+
+	// Timezone used for dates
+	tz string
+
+	// Location of tz, loaded when tz is set
+	location *time.Location
+
+	// hourly
+	// daily
+	mode ISUSMMode
+
+	// How missing data is represented
+	missing WeatherDataQueryMissing
+}
+
+// Creates a new ISUSMQueryBuilder with defaults set to optional fields
+func NewISUSMQuery() *ISUSMQueryBuilder {
+	return &ISUSMQueryBuilder{
+		tz:       defaultTimeZone,
+		location: loadTimezone(defaultTimeZone),
+		mode:     defaultISUSMMode,
+		missing:  defaultMissing,
+
+		start: time.Now(),
+		end:   time.Now(),
+	}
+}
+
+func (b *ISUSMQueryBuilder) isMissingOrTrace(value string) bool {
+	return value == b.missing.text()
+}
+
+// Appends stations to builder.station
+func (b *ISUSMQueryBuilder) Stations(stations ...string) *ISUSMQueryBuilder {
+	b.stations = append(b.stations, stations...)
+	return b
+}
+
+// Appends variables to builder.data
+func (b *ISUSMQueryBuilder) Data(data ...ISUSMVariable) *ISUSMQueryBuilder {
+	b.data = append(b.data, data...)
+	return b
+}
+
+// Sets query builder start date (defaults to today)
+func (b *ISUSMQueryBuilder) Start(t time.Time) *ISUSMQueryBuilder {
+	b.start = t
+	return b
+}
+
+// Sets query builder end date (defaults to today)
+func (b *ISUSMQueryBuilder) End(t time.Time) *ISUSMQueryBuilder {
+	b.end = t
+	return b
+}
+
+// Sets query builder timezone (defaults to Etc/UTC)
+func (b *ISUSMQueryBuilder) Timezone(tz string) *ISUSMQueryBuilder {
+	b.tz = tz
+	b.location = loadTimezone(tz)
+	return b
+}
+
+// Sets query builder mode (defaults to hourly)
+func (b *ISUSMQueryBuilder) Mode(mode ISUSMMode) *ISUSMQueryBuilder {
+	b.mode = mode
+	return b
+}
+
+// Location of the query's timezone that returned times are parsed in.
+// UTC when the timezone is not known
+func (b *ISUSMQueryBuilder) Location() *time.Location {
+	if b.location == nil {
+		return loadTimezone(b.tz)
+	}
+
+	return b.location
+}
+
+// Sets query builder missing property (defaults to M)
+func (b *ISUSMQueryBuilder) Missing(missing WeatherDataQueryMissing) *ISUSMQueryBuilder {
+	b.missing = missing
+	return b
+}
+
+// Creates url.Values with validated data from query builder
+func (b *ISUSMQueryBuilder) BuildUrl() (url.Values, error) {
+	v := url.Values{}
+
+	if b.stations == nil {
+		return nil, builderRequiredError("ISUSMQueryBuilder", "stations")
+	}
+
+	for _, s := range b.stations {
+		v.Add("station", s)
+	}
+
+	if b.data == nil {
+		return nil, builderRequiredError("ISUSMQueryBuilder", "data")
+	}
+
+	for _, d := range b.data {
+		v.Add("vars", string(d))
+	}
+
+	v.Add("mode", string(b.mode))
+
+	v.Add("year1", strconv.Itoa(b.start.Year()))
+	v.Add("month1", strconv.Itoa(int(b.start.Month())))
+	v.Add("day1", strconv.Itoa(b.start.Day()))
+
+	v.Add("year2", strconv.Itoa(b.end.Year()))
+	v.Add("month2", strconv.Itoa(int(b.end.Month())))
+	v.Add("day2", strconv.Itoa(b.end.Day()))
+
+	v.Add("tz", b.tz)
+	v.Add("format", "comma")
+	v.Add("missing", string(b.missing))
+	v.Add("todisk", "no")
+
+	return v, nil
+}
+
+type ISUSMService interface {
+	Get(ctx context.Context, query *ISUSMQueryBuilder) ([]*ISUSMData, error)
+}
+
+type IEMISUSMService struct {
+	client *Client
+}
+
+func (s *IEMISUSMService) Get(ctx context.Context, query *ISUSMQueryBuilder) ([]*ISUSMData, error) {
+	v, err := query.BuildUrl()
+
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("/cgi-bin/request/isusm.py?%s", v.Encode())
+
+	var data []*ISUSMData
+
+	err = s.client.get(ctx, url, func(body io.Reader) (err error) {
+		data, err = ParseISUSMData(body, query)
+		return err
+	})
+
+	return data, err
+}
+
+// Parse ISU Soil Moisture data from a io.Reader that reads CSV data based on a ISUSMQueryBuilder
+func ParseISUSMData(reader io.Reader, query *ISUSMQueryBuilder) ([]*ISUSMData, error) {
+	data := []*ISUSMData{}
+
+	timeLayout := "2006-01-02 15:04"
+
+	if query.mode == ISUSMDaily {
+		timeLayout = "2006-01-02"
+	}
+
+	err := readCsvRecords(reader, func(w *weatherDataIndecies, record *[]string) error {
+		d := &ISUSMData{}
+
+		err := errors.Join(
+			w.setString("station", record, &d.Station, query),
+			w.setTimeLayout("valid", timeLayout, record, &d.Time, query),
+			w.setFloat("tmpf", record, &d.TemperatureF, query),
+			w.setFloat("high", record, &d.HighF, query),
+			w.setFloat("low", record, &d.LowF, query),
+			w.setFloat("relh", record, &d.RelativeHumidity, query),
+			w.setFloat("rh", record, &d.RelativeHumidity, query),
+			w.setFloat("rh_min", record, &d.MinRelativeHumidity, query),
+			w.setFloat("rh_max", record, &d.MaxRelativeHumidity, query),
+			w.setFloat("solar", record, &d.SolarRadiation, query),
+			w.setFloat("precip", record, &d.Precip, query),
+			w.setFloat("et", record, &d.Evapotranspiration, query),
+			w.setFloat("drct", record, &d.WindDirection, query),
+			w.setFloat("speed", record, &d.WindSpeedMPH, query),
+			w.setFloat("gust", record, &d.WindGustMPH, query),
+			w.setFloat("soil04t", record, &d.SoilTemperature4InF, query),
+			w.setFloat("soil04tn", record, &d.SoilTemperature4InLowF, query),
+			w.setFloat("soil04tx", record, &d.SoilTemperature4InHighF, query),
+			w.setFloat("soil12t", record, &d.SoilTemperature12InF, query),
+			w.setFloat("soil24t", record, &d.SoilTemperature24InF, query),
+			w.setFloat("soil50t", record, &d.SoilTemperature50InF, query),
+			w.setFloat("soil12vwc", record, &d.SoilMoisture12In, query),
+			w.setFloat("soil24vwc", record, &d.SoilMoisture24In, query),
+			w.setFloat("soil50vwc", record, &d.SoilMoisture50In, query),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		for i, v := range isusmVariables {
+			d.state.parse(i, string(v), w, record, query)
+		}
+
+		data = append(data, d)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
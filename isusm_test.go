@@ -0,0 +1,98 @@
+package iem
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadISUSMFixture(t *testing.T, path string, query *ISUSMQueryBuilder) []*ISUSMData {
+	t.Helper()
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := ParseISUSMData(file, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestParseISUSMHourlyData(t *testing.T) {
+	query := NewISUSMQuery().Timezone("America/Chicago")
+	data := loadISUSMFixture(t, "./data/isusm_hourly.csv", query)
+
+	if len(data) != 4 {
+		t.Fatalf("expected 4 hours, got %d", len(data))
+	}
+
+	d := data[2]
+	valid := time.Date(2023, 10, 4, 2, 0, 0, 0, query.Location())
+
+	if d.Station != "AEEI4" || !d.Time.Equal(valid) || d.Time.Location().String() != "America/Chicago" {
+		t.Errorf("unexpected station and time %s %s", d.Station, d.Time)
+	}
+
+	if d.TemperatureF != 64.70 || d.RelativeHumidity != 84.60 || d.Precip != 0.21 || d.WindDirection != 201 {
+		t.Errorf("unexpected air values %+v", d)
+	}
+
+	if d.SoilTemperature4InF != 65.30 || d.SoilTemperature50InF != 61.36 || d.SoilMoisture12In != 22.80 || d.SoilMoisture50In != 31.20 {
+		t.Errorf("unexpected soil values %+v", d)
+	}
+
+	if !data[3].IsMissing(ISUSMWindDirection) || data[3].WindSpeedMPH != 2.91 {
+		t.Errorf("unexpected wind with missing direction %+v", data[3])
+	}
+
+	// Observed zeros and variables that are not in the response
+	if data[0].IsMissing(ISUSMSolar) || data[0].IsMissing(ISUSMPrecip) || !data[0].IsMissing(ISUSMHighF) {
+		t.Errorf("unexpected missing variables %+v", data[0])
+	}
+}
+
+func TestParseISUSMDataMissingEmpty(t *testing.T) {
+	body := "station,valid,tmpf,precip\nAEEI4,2023-10-04 00:00,,0.00\n"
+	data, err := ParseISUSMData(strings.NewReader(body), NewISUSMQuery().Missing(MissingEmpty))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !data[0].IsMissing(ISUSMTempF) || data[0].IsMissing(ISUSMPrecip) {
+		t.Errorf("expected an empty tmpf to be missing %+v", data[0])
+	}
+}
+
+func TestParseISUSMDailyData(t *testing.T) {
+	query := NewISUSMQuery().Mode(ISUSMDaily)
+	data := loadISUSMFixture(t, "./data/isusm_daily.csv", query)
+
+	if len(data) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(data))
+	}
+
+	d := data[1]
+
+	if !d.Time.Equal(time.Date(2023, 10, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date %s", d.Time)
+	}
+
+	// rh is the daily average relative humidity
+	if d.HighF != 72.50 || d.LowF != 59.60 || d.RelativeHumidity != 76.30 || d.MinRelativeHumidity != 48.90 || d.MaxRelativeHumidity != 97.80 {
+		t.Errorf("unexpected air values %+v", d)
+	}
+
+	if d.SoilTemperature4InLowF != 62.30 || d.SoilTemperature4InHighF != 67.60 || d.WindGustMPH != 31.10 || d.Evapotranspiration != 0.09 {
+		t.Errorf("unexpected values %+v", d)
+	}
+}
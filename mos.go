@@ -0,0 +1,299 @@
+package iem
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"time"
+)
+
+type MOSModel string
+
+const (
+	MOSGFS  MOSModel = "GFS"  // GFS MOS (MAV)
+	MOSGFSX MOSModel = "GFSX" // GFS Extended MOS (MEX)
+	MOSNAM  MOSModel = "NAM"  // NAM MOS (MET)
+	MOSNBS  MOSModel = "NBS"  // National Blend of Models short range
+	MOSNBE  MOSModel = "NBE"  // National Blend of Models extended range
+)
+
+// MOSForecast is a single forecast hour of a MOS run for a station.
+// Property names match IEMWeatherData where the variable is also observed.
+// Properties are nil when the model does not forecast them for the hour
+type MOSForecast struct {
+	Station string    `json:"station"`
+	Model   MOSModel  `json:"model"`
+	Runtime time.Time `json:"runtime"` // Model run time
+	Time    time.Time `json:"ftime"`   // Forecast valid time
+
+	TemperatureF       *float64 `json:"tmp,omitempty"` // Air Temperature [F] (tmp)
+	DewPointF          *float64 `json:"dpt,omitempty"` // Dew Point [F] (dpt)
+	MaxMinTemperatureF *float64 `json:"n_x,omitempty"` // 12 hour Max/Min Temperature [F] (n_x)
+
+	WindDirection  *float64 `json:"wdr,omitempty"` // Wind Direction [deg] (wdr)
+	WindSpeedKnots *float64 `json:"wsp,omitempty"` // Wind Speed [knots] (wsp)
+	WindGustKnots  *float64 `json:"gst,omitempty"` // Wind Gust [knots] (gst)
+
+	CloudCoverage       string   `json:"cld,omitempty"` // Sky Cover (CL, FW, SC, BK, OV) (cld)
+	CeilingCategory     *float64 `json:"cig,omitempty"` // Ceiling Height Category (cig)
+	VisibilityCategory  *float64 `json:"vis,omitempty"` // Visibility Category (vis)
+	ObstructionToVision string   `json:"obv,omitempty"` // Obstruction to Vision (obv)
+
+	PrecipProbability6HR  *float64 `json:"p06,omitempty"` // 6 hour Probability of Precipitation [%] (p06)
+	PrecipProbability12HR *float64 `json:"p12,omitempty"` // 12 hour Probability of Precipitation [%] (p12)
+	PrecipCategory6HR     *float64 `json:"q06,omitempty"` // 6 hour Quantitative Precipitation Category (q06)
+	PrecipCategory12HR    *float64 `json:"q12,omitempty"` // 12 hour Quantitative Precipitation Category (q12)
+	PrecipType            string   `json:"typ,omitempty"` // Precipitation Type (typ)
+
+	ThunderProbability6HR  string `json:"t06,omitempty"` // 6 hour Thunderstorm/Severe Probability [%] (t06)
+	ThunderProbability12HR string `json:"t12,omitempty"` // 12 hour Thunderstorm/Severe Probability [%] (t12)
+
+	SnowCategory *float64 `json:"snw,omitempty"` // 24 hour Snowfall Category (snw)
+}
+
+// Float returns the forecast value of an observed data column.
+// False when the model does not forecast the column for this hour
+func (f *MOSForecast) Float(column WeatherDataData) (float64, bool) {
+	var value *float64
+
+	switch column {
+	case TempF:
+		value = f.TemperatureF
+	case TempC:
+		if f.TemperatureF != nil {
+			return (*f.TemperatureF - 32) * 5 / 9, true
+		}
+	case DewPointF:
+		value = f.DewPointF
+	case DewPointC:
+		if f.DewPointF != nil {
+			return (*f.DewPointF - 32) * 5 / 9, true
+		}
+	case WindDirection:
+		value = f.WindDirection
+	case WindSpeedKnots:
+		value = f.WindSpeedKnots
+	case WindGustKnots:
+		value = f.WindGustKnots
+	}
+
+	if value == nil {
+		return 0, false
+	}
+
+	return *value, true
+}
+
+type iemMOSJsonResponse struct {
+	Data []*MOSForecast `json:"data"`
+}
+
+type MOSService interface {
+	// Gets the forecast hours of a model run. A zero runtime gets the latest run
+	GetForecasts(ctx context.Context, stationId string, model MOSModel, runtime time.Time) ([]*MOSForecast, error)
+}
+
+type IEMMOSService struct {
+	client *Client
+}
+
+func (s *IEMMOSService) GetForecasts(ctx context.Context, stationId string, model MOSModel, runtime time.Time) ([]*MOSForecast, error) {
+	v := url.Values{}
+	v.Add("station", stationId)
+	v.Add("model", string(model))
+
+	if !runtime.IsZero() {
+		v.Add("runtime", runtime.UTC().Format("2006-01-02 15:04"))
+	}
+
+	url := fmt.Sprintf("/api/1/mos.json?%s", v.Encode())
+	var mosResponse iemMOSJsonResponse
+
+	err := s.client.getJson(ctx, url, &mosResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, forecast := range mosResponse.Data {
+		if forecast.Station == "" {
+			forecast.Station = stationId
+		}
+	}
+
+	return mosResponse.Data, nil
+}
+
+// MOSVerificationPair is a forecast hour paired with the observation closest to it
+type MOSVerificationPair struct {
+	Forecast    *MOSForecast    `json:"forecast"`
+	Observation *IEMWeatherData `json:"observation"`
+	Offset      time.Duration   `json:"offset"` // Observation time minus forecast time
+}
+
+// MOSErrorStats are forecast minus observed error statistics for a variable
+type MOSErrorStats struct {
+	Count                int     `json:"count"`
+	MeanError            float64 `json:"mean_error"` // Bias
+	MeanAbsoluteError    float64 `json:"mean_absolute_error"`
+	RootMeanSquaredError float64 `json:"root_mean_squared_error"`
+
+	sum, sumAbsolute, sumSquared float64
+}
+
+func (s *MOSErrorStats) add(err float64) {
+	s.Count++
+	s.sum += err
+	s.sumAbsolute += math.Abs(err)
+	s.sumSquared += err * err
+
+	n := float64(s.Count)
+	s.MeanError = s.sum / n
+	s.MeanAbsoluteError = s.sumAbsolute / n
+	s.RootMeanSquaredError = math.Sqrt(s.sumSquared / n)
+}
+
+// MOSVerification is the result of verifying MOS forecasts against observations
+type MOSVerification struct {
+	Pairs []*MOSVerificationPair             `json:"pairs"`
+	Stats map[WeatherDataData]*MOSErrorStats `json:"stats"`
+}
+
+type mosVerifiedColumn struct {
+	column  WeatherDataData
+	angular bool
+}
+
+var mosVerifiedColumns = []mosVerifiedColumn{
+	{column: TempF},
+	{column: DewPointF},
+	{column: WindSpeedKnots},
+	{column: WindGustKnots},
+	{column: WindDirection, angular: true},
+}
+
+// VerifyMOS pairs each forecast hour with the observation at the same station
+// closest in time, within maxOffset, and computes error statistics for the
+// temperature, dew point and wind columns present in both
+func VerifyMOS(forecasts []*MOSForecast, observations []*IEMWeatherData, maxOffset time.Duration) *MOSVerification {
+	verification := &MOSVerification{
+		Pairs: []*MOSVerificationPair{},
+		Stats: map[WeatherDataData]*MOSErrorStats{},
+	}
+
+	byStation := map[string][]*IEMWeatherData{}
+
+	for _, observation := range observations {
+		if observation.Time == nil {
+			continue
+		}
+
+		byStation[observation.Station] = append(byStation[observation.Station], observation)
+	}
+
+	for _, stationObservations := range byStation {
+		sort.Slice(stationObservations, func(i, j int) bool {
+			return stationObservations[i].Time.Before(*stationObservations[j].Time)
+		})
+	}
+
+	for _, forecast := range forecasts {
+		stationObservations, ok := byStation[forecast.Station]
+
+		if !ok {
+			stationObservations = byStation[mosObservationStation(forecast.Station)]
+		}
+
+		observation, offset, ok := closestObservation(stationObservations, forecast.Time)
+
+		if !ok || offset > maxOffset || offset < -maxOffset {
+			continue
+		}
+
+		verification.Pairs = append(verification.Pairs, &MOSVerificationPair{
+			Forecast:    forecast,
+			Observation: observation,
+			Offset:      offset,
+		})
+
+		for _, verified := range mosVerifiedColumns {
+			forecasted, ok := forecast.Float(verified.column)
+
+			if !ok {
+				continue
+			}
+
+			// Observations that were missing are skipped, observed zeros are kept
+			if observation.IsMissing(verified.column) {
+				continue
+			}
+
+			observed, _ := observation.Float(verified.column)
+
+			err := forecasted - observed
+
+			if verified.angular {
+				err = math.Mod(err+540, 360) - 180
+			}
+
+			stats, ok := verification.Stats[verified.column]
+
+			if !ok {
+				stats = &MOSErrorStats{}
+				verification.Stats[verified.column] = stats
+			}
+
+			stats.add(err)
+		}
+	}
+
+	return verification
+}
+
+// MOS uses ICAO ids (KDSM) while ASOS observations use the IEM id (DSM)
+func mosObservationStation(stationId string) string {
+	if len(stationId) == 4 && stationId[0] == 'K' {
+		return stationId[1:]
+	}
+
+	return stationId
+}
+
+// Finds the observation closest to t in observations sorted by time
+func closestObservation(observations []*IEMWeatherData, t time.Time) (*IEMWeatherData, time.Duration, bool) {
+	if len(observations) == 0 {
+		return nil, 0, false
+	}
+
+	i := sort.Search(len(observations), func(i int) bool {
+		return !observations[i].Time.Before(t)
+	})
+
+	best := -1
+	var bestOffset time.Duration
+
+	for _, candidate := range []int{i - 1, i} {
+		if candidate < 0 || candidate >= len(observations) {
+			continue
+		}
+
+		offset := observations[candidate].Time.Sub(t)
+
+		if best == -1 || absDuration(offset) < absDuration(bestOffset) {
+			best = candidate
+			bestOffset = offset
+		}
+	}
+
+	return observations[best], bestOffset, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
@@ -0,0 +1,148 @@
+package iem
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadMOSFixture(t *testing.T) []*MOSForecast {
+	t.Helper()
+
+	body, err := os.ReadFile("./data/mos_klnk_gfs.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+
+	defer server.Close()
+
+	client := NewClientWithOptions(WithBaseUrl(server.URL))
+	forecasts, err := client.MOS().GetForecasts(context.Background(), "KLNK", MOSGFS, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return forecasts
+}
+
+func loadWeatherFixture(t *testing.T, path string, query *WeatherDataQueryBuilder) []*IEMWeatherData {
+	t.Helper()
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := ParseWeatherData(file, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func assertStats(t *testing.T, column WeatherDataData, stats *MOSErrorStats, count int, mean float64, absolute float64, rmse float64) {
+	t.Helper()
+
+	if stats == nil {
+		t.Fatalf("%s: expected stats", column)
+	}
+
+	if stats.Count != count ||
+		math.Abs(stats.MeanError-mean) > 0.001 ||
+		math.Abs(stats.MeanAbsoluteError-absolute) > 0.001 ||
+		math.Abs(stats.RootMeanSquaredError-rmse) > 0.001 {
+		t.Errorf("%s: got %+v, want count %d, mean %f, absolute %f, rmse %f", column, stats, count, mean, absolute, rmse)
+	}
+}
+
+func TestGetMOSForecasts(t *testing.T) {
+	forecasts := loadMOSFixture(t)
+
+	if len(forecasts) != 5 {
+		t.Fatalf("expected 5 forecasts, got %d", len(forecasts))
+	}
+
+	first := forecasts[0]
+
+	if first.TemperatureF == nil || *first.TemperatureF != 65 || first.CloudCoverage != "BK" || first.MaxMinTemperatureF != nil {
+		t.Errorf("unexpected forecast %+v", first)
+	}
+
+	if !first.Time.Equal(time.Date(2023, 10, 4, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected forecast time %s", first.Time)
+	}
+
+	if c, ok := first.Float(TempC); !ok || math.Abs(c-18.333) > 0.001 {
+		t.Errorf("expected temperature in C, got %f %v", c, ok)
+	}
+}
+
+func TestVerifyMOS(t *testing.T) {
+	forecasts := loadMOSFixture(t)
+	observations := loadWeatherFixture(t, "./data/full_weather_data.csv", NewWeatherDataQuery())
+
+	verification := VerifyMOS(forecasts, observations, 30*time.Minute)
+
+	// The last forecast hour is after the observations
+	if len(verification.Pairs) != 4 {
+		t.Fatalf("expected 4 pairs, got %d", len(verification.Pairs))
+	}
+
+	if verification.Pairs[0].Offset != -6*time.Minute {
+		t.Errorf("expected the 02:54 observation to be paired with 03:00, got %s", verification.Pairs[0].Offset)
+	}
+
+	assertStats(t, TempF, verification.Stats[TempF], 4, -0.25, 1.25, 1.5)
+	assertStats(t, DewPointF, verification.Stats[DewPointF], 4, 0, 1, math.Sqrt(1.5))
+	assertStats(t, WindSpeedKnots, verification.Stats[WindSpeedKnots], 4, 1.25, 1.25, 1.5)
+
+	// 350 forecast against 240 is 110 and 10 against 310 wraps to 60
+	assertStats(t, WindDirection, verification.Stats[WindDirection], 4, 35, 50, math.Sqrt((100+400+12100+3600)/4.0))
+
+	// Gusts were not reported at the paired observations
+	if _, ok := verification.Stats[WindGustKnots]; ok {
+		t.Errorf("expected no gust stats, got %+v", verification.Stats[WindGustKnots])
+	}
+}
+
+func TestVerifyMOSObservedZero(t *testing.T) {
+	query := NewWeatherDataQuery().Data(TempF, DewPointF)
+	observations, err := ParseWeatherData(strings.NewReader("station,valid,tmpf,dwpf\nLNK,2023-01-04 03:00,0.00,M\n"), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	temperature, dewPoint := 2.0, -5.0
+	forecasts := []*MOSForecast{{
+		Station:      "KLNK",
+		Time:         time.Date(2023, 1, 4, 3, 0, 0, 0, time.UTC),
+		TemperatureF: &temperature,
+		DewPointF:    &dewPoint,
+	}}
+
+	verification := VerifyMOS(forecasts, observations, time.Hour)
+
+	// 0F was observed and the missing dew point is skipped
+	assertStats(t, TempF, verification.Stats[TempF], 1, 2, 2, 2)
+
+	if _, ok := verification.Stats[DewPointF]; ok {
+		t.Errorf("expected no dew point stats, got %+v", verification.Stats[DewPointF])
+	}
+}
@@ -0,0 +1,292 @@
+package iem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OutlookCategory string
+
+const (
+	OutlookCategorical OutlookCategory = "CATEGORICAL"
+	OutlookTornado     OutlookCategory = "TORNADO"
+	OutlookHail        OutlookCategory = "HAIL"
+	OutlookWind        OutlookCategory = "WIND"
+	OutlookAny         OutlookCategory = "ANY SEVERE" // Day 3 probabilistic
+)
+
+// OutlookRisk is a categorical convective outlook risk level. Risks are
+// ordered so a higher risk compares greater
+type OutlookRisk int
+
+const (
+	RiskNone OutlookRisk = iota
+	RiskTSTM             // General Thunderstorms
+	RiskMRGL             // Marginal
+	RiskSLGT             // Slight
+	RiskENH              // Enhanced
+	RiskMDT              // Moderate
+	RiskHIGH             // High
+)
+
+var outlookRiskNames = []string{"NONE", "TSTM", "MRGL", "SLGT", "ENH", "MDT", "HIGH"}
+
+func (r OutlookRisk) String() string {
+	if r < 0 || int(r) >= len(outlookRiskNames) {
+		return outlookRiskNames[RiskNone]
+	}
+
+	return outlookRiskNames[r]
+}
+
+func parseOutlookRisk(threshold string) (OutlookRisk, bool) {
+	for risk, name := range outlookRiskNames {
+		if risk != int(RiskNone) && name == threshold {
+			return OutlookRisk(risk), true
+		}
+	}
+
+	return RiskNone, false
+}
+
+func (r OutlookRisk) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+func (r *OutlookRisk) UnmarshalJSON(data []byte) error {
+	var name string
+
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	if name == "" || name == RiskNone.String() {
+		*r = RiskNone
+		return nil
+	}
+
+	risk, ok := parseOutlookRisk(name)
+
+	if !ok {
+		return fmt.Errorf("unknown outlook risk %s", name)
+	}
+
+	*r = risk
+
+	return nil
+}
+
+// OutlookFeature is a single outlook area. Categorical areas have a Risk and
+// probabilistic areas have a Probability or are a significant severe (SIGN) area
+type OutlookFeature struct {
+	Day         int             `json:"day"`
+	Category    OutlookCategory `json:"category"`
+	Threshold   string          `json:"threshold"`             // TSTM, SLGT, 0.05, SIGN, etc
+	Risk        OutlookRisk     `json:"risk,omitempty"`        // Categorical risk
+	Probability float64         `json:"probability,omitempty"` // Probabilistic risk [0-1]
+	Significant bool            `json:"significant,omitempty"` // Significant severe area (SIGN)
+	Issue       time.Time       `json:"issue"`
+	Expire      time.Time       `json:"expire"`
+	Geometry    MultiPolygon    `json:"geometry"`
+}
+
+// Outlook is an SPC convective outlook issuance
+type Outlook struct {
+	Day      int               `json:"day"`
+	Valid    time.Time         `json:"valid"`
+	Features []*OutlookFeature `json:"features"`
+}
+
+type iemOutlookFeatureProperties struct {
+	Day       int    `json:"day"`
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+	Issue     string `json:"issue"`
+	Expire    string `json:"expire"`
+}
+
+// OutlookQuery selects the outlook for a day. Valid is the time the outlook
+// should be in effect for, the latest outlook issued for it is returned
+type OutlookQuery struct {
+	Day      int             // 1, 2 or 3
+	Category OutlookCategory // Empty gets every category
+	Valid    time.Time
+}
+
+type OutlookService interface {
+	GetOutlook(ctx context.Context, query OutlookQuery) (*Outlook, error)
+
+	// Gets the highest categorical risk at a station in the day 1 outlook valid at t
+	GetStationRisk(ctx context.Context, station *Station, t time.Time) (OutlookRisk, error)
+}
+
+type IEMOutlookService struct {
+	client *Client
+}
+
+func (s *IEMOutlookService) GetOutlook(ctx context.Context, query OutlookQuery) (*Outlook, error) {
+	v := url.Values{}
+	v.Add("d", strconv.Itoa(query.Day))
+	v.Add("ts", query.Valid.UTC().Format("200601021504"))
+
+	if query.Category != "" {
+		v.Add("cat", strings.ToLower(string(query.Category)))
+	}
+
+	url := fmt.Sprintf("/geojson/outlook.py?%s", v.Encode())
+	var collection geoJSONFeatureCollection
+
+	err := s.client.getJson(ctx, url, &collection)
+
+	if err != nil {
+		return nil, err
+	}
+
+	outlook := &Outlook{
+		Day:      query.Day,
+		Valid:    query.Valid,
+		Features: []*OutlookFeature{},
+	}
+
+	for _, f := range collection.Features {
+		feature, err := outlookFeature(f)
+
+		if err != nil {
+			return nil, err
+		}
+
+		outlook.Features = append(outlook.Features, feature)
+	}
+
+	return outlook, nil
+}
+
+func (s *IEMOutlookService) GetStationRisk(ctx context.Context, station *Station, t time.Time) (OutlookRisk, error) {
+	outlook, err := s.GetOutlook(ctx, OutlookQuery{
+		Day:      1,
+		Category: OutlookCategorical,
+		Valid:    t,
+	})
+
+	if err != nil {
+		return RiskNone, err
+	}
+
+	return outlook.HighestRisk(station), nil
+}
+
+func outlookFeature(f *geoJSONFeature) (*OutlookFeature, error) {
+	var properties iemOutlookFeatureProperties
+
+	if err := json.Unmarshal(f.Properties, &properties); err != nil {
+		return nil, err
+	}
+
+	geometry, err := f.Geometry.multiPolygon()
+
+	if err != nil {
+		return nil, err
+	}
+
+	feature := &OutlookFeature{
+		Day:       properties.Day,
+		Category:  OutlookCategory(strings.ToUpper(properties.Category)),
+		Threshold: properties.Threshold,
+		Geometry:  geometry,
+	}
+
+	if feature.Issue, err = parseIEMTime(properties.Issue); err != nil && properties.Issue != "" {
+		return nil, fmt.Errorf("error parsing issue [%w]", err)
+	}
+
+	if feature.Expire, err = parseIEMTime(properties.Expire); err != nil && properties.Expire != "" {
+		return nil, fmt.Errorf("error parsing expire [%w]", err)
+	}
+
+	if risk, ok := parseOutlookRisk(properties.Threshold); ok {
+		feature.Risk = risk
+	} else if properties.Threshold == "SIGN" {
+		feature.Significant = true
+	} else if probability, err := strconv.ParseFloat(properties.Threshold, 64); err == nil {
+		feature.Probability = probability
+	}
+
+	return feature, nil
+}
+
+// HighestRisk is the highest categorical risk of the outlook containing the station
+func (o *Outlook) HighestRisk(station *Station) OutlookRisk {
+	highest := RiskNone
+
+	for _, feature := range o.Features {
+		if feature.Category != OutlookCategorical || feature.Risk <= highest {
+			continue
+		}
+
+		if feature.Geometry.ContainsStation(station) {
+			highest = feature.Risk
+		}
+	}
+
+	return highest
+}
+
+// HighestProbability is the highest probability of a probabilistic category
+// containing the station and whether it is inside a significant severe area
+func (o *Outlook) HighestProbability(station *Station, category OutlookCategory) (float64, bool) {
+	highest := 0.0
+	significant := false
+
+	for _, feature := range o.Features {
+		if feature.Category != category || !feature.Geometry.ContainsStation(station) {
+			continue
+		}
+
+		if feature.Significant {
+			significant = true
+		}
+
+		if feature.Probability > highest {
+			highest = feature.Probability
+		}
+	}
+
+	return highest, significant
+}
+
+// StationRisks gets the highest day 1 categorical risk at a station for each
+// day with observations, keyed by date (2006-01-02). Observations should be
+// for the station
+func StationRisks(ctx context.Context, outlooks OutlookService, station *Station, data []*IEMWeatherData) (map[string]OutlookRisk, error) {
+	risks := map[string]OutlookRisk{}
+
+	for _, d := range data {
+		if d.Time == nil {
+			continue
+		}
+
+		date := d.Time.Format("2006-01-02")
+
+		if _, ok := risks[date]; ok {
+			continue
+		}
+
+		// Day 1 outlooks are valid from 12Z to 12Z, midday is always covered
+		valid := time.Date(d.Time.Year(), d.Time.Month(), d.Time.Day(), 18, 0, 0, 0, time.UTC)
+
+		risk, err := outlooks.GetStationRisk(ctx, station, valid)
+
+		if err != nil {
+			return nil, err
+		}
+
+		risks[date] = risk
+	}
+
+	return risks, nil
+}
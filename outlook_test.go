@@ -0,0 +1,165 @@
+package iem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+var (
+	outlookLNK = &Station{Id: "LNK", Latitude: 40.85, Longitude: -96.76}
+	outlookOMA = &Station{Id: "OMA", Latitude: 41.3, Longitude: -95.9}
+	outlookDSM = &Station{Id: "DSM", Latitude: 41.53, Longitude: -93.66}
+	outlookDEN = &Station{Id: "DEN", Latitude: 39.86, Longitude: -104.67}
+)
+
+func newOutlookFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	body, err := os.ReadFile("./data/spc_outlook_day1.geojson")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func loadOutlookFixture(t *testing.T) *Outlook {
+	t.Helper()
+
+	client := NewClientWithOptions(WithBaseUrl(newOutlookFixtureServer(t).URL))
+	valid := time.Date(2023, 10, 4, 18, 0, 0, 0, time.UTC)
+
+	outlook, err := client.Outlooks().GetOutlook(context.Background(), OutlookQuery{Day: 1, Valid: valid})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return outlook
+}
+
+func TestGetOutlook(t *testing.T) {
+	outlook := loadOutlookFixture(t)
+
+	if len(outlook.Features) != 6 {
+		t.Fatalf("expected 6 features, got %d", len(outlook.Features))
+	}
+
+	slight := outlook.Features[2]
+
+	if slight.Risk != RiskSLGT || slight.Category != OutlookCategorical || len(slight.Geometry) != 2 {
+		t.Errorf("unexpected feature %+v", slight)
+	}
+
+	if !slight.Issue.Equal(time.Date(2023, 10, 4, 16, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected issue %s", slight.Issue)
+	}
+
+	if outlook.Features[4].Probability != 0.05 || !outlook.Features[5].Significant {
+		t.Errorf("unexpected probabilistic features %+v %+v", outlook.Features[4], outlook.Features[5])
+	}
+}
+
+func TestOutlookHighestRisk(t *testing.T) {
+	outlook := loadOutlookFixture(t)
+
+	tests := []struct {
+		station  *Station
+		expected OutlookRisk
+	}{
+		{outlookLNK, RiskSLGT},
+		{outlookOMA, RiskSLGT},
+		{outlookDSM, RiskMRGL},
+		{outlookDEN, RiskNone},
+	}
+
+	for _, test := range tests {
+		if risk := outlook.HighestRisk(test.station); risk != test.expected {
+			t.Errorf("%s: risk = %s, want %s", test.station.Id, risk, test.expected)
+		}
+	}
+}
+
+func TestOutlookHighestProbability(t *testing.T) {
+	outlook := loadOutlookFixture(t)
+
+	tests := []struct {
+		station     *Station
+		probability float64
+		significant bool
+	}{
+		{outlookLNK, 0.05, true},
+		{outlookOMA, 0.02, false}, // Inside the hole of the 5% area
+		{outlookDSM, 0.02, false},
+		{outlookDEN, 0, false},
+	}
+
+	for _, test := range tests {
+		probability, significant := outlook.HighestProbability(test.station, OutlookTornado)
+
+		if probability != test.probability || significant != test.significant {
+			t.Errorf("%s: got %f %v, want %f %v", test.station.Id, probability, significant, test.probability, test.significant)
+		}
+	}
+
+	if probability, _ := outlook.HighestProbability(outlookLNK, OutlookHail); probability != 0 {
+		t.Errorf("expected no hail probability, got %f", probability)
+	}
+}
+
+func TestGetStationRisk(t *testing.T) {
+	client := NewClientWithOptions(WithBaseUrl(newOutlookFixtureServer(t).URL))
+
+	risk, err := client.Outlooks().GetStationRisk(context.Background(), outlookLNK, time.Date(2023, 10, 4, 18, 0, 0, 0, time.UTC))
+
+	if err != nil || risk != RiskSLGT {
+		t.Errorf("expected SLGT, got %s %v", risk, err)
+	}
+}
+
+func TestOutlookRiskJSON(t *testing.T) {
+	outlook := loadOutlookFixture(t)
+	body, err := json.Marshal(outlook)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Outlook
+
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, feature := range decoded.Features {
+		if feature.Risk != outlook.Features[i].Risk {
+			t.Errorf("feature %d: risk = %s, want %s", i, feature.Risk, outlook.Features[i].Risk)
+		}
+	}
+
+	if decoded.HighestRisk(outlookLNK) != RiskSLGT {
+		t.Errorf("expected decoded outlook to have SLGT at LNK")
+	}
+
+	var risk OutlookRisk
+
+	if err := json.Unmarshal([]byte(`"NONE"`), &risk); err != nil || risk != RiskNone {
+		t.Errorf("expected NONE, got %s %v", risk, err)
+	}
+
+	if err := json.Unmarshal([]byte(`"EXTREME"`), &risk); err == nil {
+		t.Error("expected unknown risk error")
+	}
+}
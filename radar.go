@@ -0,0 +1,149 @@
+package iem
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// IEM networks with radar sites as their stations
+const (
+	NEXRADNetwork = "NEXRAD" // WSR-88D radars
+	TDWRNetwork   = "TWDR"   // Terminal Doppler Weather Radars
+)
+
+// RadarProduct is a RIDGE product available for a radar
+type RadarProduct struct {
+	Id   string `json:"id"`   // N0Q, N0U, etc
+	Name string `json:"name"` // Base Reflectivity, etc
+}
+
+// RadarScan is a RIDGE image of a radar product at a time
+type RadarScan struct {
+	Radar   string    `json:"radar"`
+	Product string    `json:"product"`
+	Time    time.Time `json:"time"`
+	URL     string    `json:"url"` // Georeferenced PNG of the scan
+}
+
+type iemRadarProductsJsonResponse struct {
+	Products []*RadarProduct `json:"products"`
+}
+
+type iemRadarScansJsonResponse struct {
+	Scans []struct {
+		Ts string `json:"ts"`
+	} `json:"scans"`
+}
+
+type RadarService interface {
+	// Gets the NEXRAD radar sites
+	GetSites(ctx context.Context) ([]*Station, error)
+
+	// Gets the products available for a radar at a time
+	GetProducts(ctx context.Context, radarId string, t time.Time) ([]*RadarProduct, error)
+
+	// Gets the scans of a radar product between start and end
+	GetScans(ctx context.Context, radarId string, product string, start time.Time, end time.Time) ([]*RadarScan, error)
+
+	// Gets the NEXRAD radar site closest to a station and its distance [km]
+	GetClosestSite(ctx context.Context, station *Station) (*Station, float64, error)
+}
+
+type IEMRadarService struct {
+	client *Client
+}
+
+const radarTimeLayout = "2006-01-02T15:04Z"
+
+func (s *IEMRadarService) GetSites(ctx context.Context) ([]*Station, error) {
+	return s.client.Stations().GetStations(ctx, NEXRADNetwork)
+}
+
+func (s *IEMRadarService) GetProducts(ctx context.Context, radarId string, t time.Time) ([]*RadarProduct, error) {
+	v := url.Values{}
+	v.Add("operation", "products")
+	v.Add("radar", radarId)
+	v.Add("start", t.UTC().Format(radarTimeLayout))
+
+	url := fmt.Sprintf("/json/radar.py?%s", v.Encode())
+	var productsResponse iemRadarProductsJsonResponse
+
+	err := s.client.getJson(ctx, url, &productsResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return productsResponse.Products, nil
+}
+
+func (s *IEMRadarService) GetScans(ctx context.Context, radarId string, product string, start time.Time, end time.Time) ([]*RadarScan, error) {
+	v := url.Values{}
+	v.Add("operation", "list")
+	v.Add("radar", radarId)
+	v.Add("product", product)
+	v.Add("start", start.UTC().Format(radarTimeLayout))
+	v.Add("end", end.UTC().Format(radarTimeLayout))
+
+	url := fmt.Sprintf("/json/radar.py?%s", v.Encode())
+	var scansResponse iemRadarScansJsonResponse
+
+	err := s.client.getJson(ctx, url, &scansResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scans := []*RadarScan{}
+
+	for _, scan := range scansResponse.Scans {
+		t, err := parseIEMTime(scan.Ts)
+
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ts [%w]", err)
+		}
+
+		scans = append(scans, &RadarScan{
+			Radar:   radarId,
+			Product: product,
+			Time:    t,
+			URL:     s.client.ridgeUrl(radarId, product, t),
+		})
+	}
+
+	return scans, nil
+}
+
+func (s *IEMRadarService) GetClosestSite(ctx context.Context, station *Station) (*Station, float64, error) {
+	sites, err := s.GetSites(ctx)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	site, distance := ClosestStation(sites, station.Latitude, station.Longitude)
+
+	if site == nil {
+		return nil, 0, IEMNotFoundError{Detail: "no radar sites found", Code: 404}
+	}
+
+	return site, distance, nil
+}
+
+// URL of the RIDGE archive image of a radar product scan
+func (c *Client) ridgeUrl(radarId string, product string, t time.Time) string {
+	t = t.UTC()
+
+	return fmt.Sprintf(
+		"%s/archive/data/%s/GIS/ridge/%s/%s/%s_%s_%s.png",
+		c.baseUrl,
+		t.Format("2006/01/02"),
+		radarId,
+		product,
+		radarId,
+		product,
+		t.Format("200601021504"),
+	)
+}
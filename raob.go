@@ -0,0 +1,387 @@
+package iem
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// RAOBLevel is a single level of an upper air sounding.
+// Properties are nil when the level did not report them
+type RAOBLevel struct {
+	Pressure       *float64 `json:"pres"` // Pressure [hPa] (pres)
+	Height         *float64 `json:"hght"` // Geopotential Height [m] (hght)
+	TemperatureC   *float64 `json:"tmpc"` // Air Temperature [C] (tmpc)
+	DewPointC      *float64 `json:"dwpc"` // Dew Point [C] (dwpc)
+	WindDirection  *float64 `json:"drct"` // Wind Direction [deg] (drct)
+	WindSpeedKnots *float64 `json:"sknt"` // Wind Speed [knots] (sknt)
+}
+
+// RAOBProfile is an upper air sounding launched from a station at a time
+type RAOBProfile struct {
+	Station string       `json:"station"`
+	Valid   time.Time    `json:"valid"`
+	Levels  []*RAOBLevel `json:"profile"`
+}
+
+type iemRAOBJsonResponse struct {
+	Profiles []*RAOBProfile `json:"profiles"`
+}
+
+type RAOBService interface {
+	GetProfile(ctx context.Context, stationId string, valid time.Time) (*RAOBProfile, error)
+}
+
+type IEMRAOBService struct {
+	client *Client
+}
+
+func (s *IEMRAOBService) GetProfile(ctx context.Context, stationId string, valid time.Time) (*RAOBProfile, error) {
+	v := url.Values{}
+	v.Add("station", stationId)
+	v.Add("ts", valid.UTC().Format("200601021504"))
+
+	url := fmt.Sprintf("/json/raob.py?%s", v.Encode())
+	var raobResponse iemRAOBJsonResponse
+
+	err := s.client.getJson(ctx, url, &raobResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raobResponse.Profiles) == 0 {
+		return nil, IEMNotFoundError{
+			Detail: fmt.Sprintf("no sounding found for %s at %s", stationId, valid.UTC().Format(time.RFC3339)),
+			Code:   404,
+		}
+	}
+
+	return raobResponse.Profiles[0], nil
+}
+
+const (
+	gravity = 9.80665 // [m/s^2]
+	rd      = 287.04  // Gas constant for dry air [J/kg/K]
+	cpd     = 1005.7  // Specific heat of dry air at constant pressure [J/kg/K]
+	lv      = 2.501e6 // Latent heat of vaporization [J/kg]
+	epsilon = 0.622   // Ratio of gas constants of dry air and water vapor
+	kappa   = rd / cpd
+	kelvin  = 273.15
+)
+
+// thermoLevel is a sounding level with the values needed for thermodynamic calculations
+type thermoLevel struct {
+	pressure     float64 // [hPa]
+	height       float64 // [m]
+	temperatureC float64
+	dewPointC    float64 // NaN when missing
+}
+
+// Levels with pressure, height and temperature ordered from the surface up
+func (p *RAOBProfile) thermoLevels() []thermoLevel {
+	levels := []thermoLevel{}
+
+	for _, level := range p.Levels {
+		if level.Pressure == nil || level.Height == nil || level.TemperatureC == nil {
+			continue
+		}
+
+		dewPoint := math.NaN()
+
+		if level.DewPointC != nil {
+			dewPoint = *level.DewPointC
+		}
+
+		levels = append(levels, thermoLevel{
+			pressure:     *level.Pressure,
+			height:       *level.Height,
+			temperatureC: *level.TemperatureC,
+			dewPointC:    dewPoint,
+		})
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].pressure > levels[j].pressure
+	})
+
+	return levels
+}
+
+// Saturation vapor pressure [hPa] over water (Bolton 1980)
+func saturationVaporPressure(temperatureC float64) float64 {
+	return 6.112 * math.Exp(17.67*temperatureC/(temperatureC+243.5))
+}
+
+// Mixing ratio [kg/kg] of air with the given vapor pressure
+func mixingRatio(vaporPressure, pressure float64) float64 {
+	return epsilon * vaporPressure / (pressure - vaporPressure)
+}
+
+func virtualTemperature(temperatureK, mixingRatio float64) float64 {
+	return temperatureK * (1 + mixingRatio/epsilon) / (1 + mixingRatio)
+}
+
+// Pseudo-adiabatic lapse rate dT/dp [K/hPa] for saturated air
+func moistLapseRate(temperatureK, pressure float64) float64 {
+	ws := mixingRatio(saturationVaporPressure(temperatureK-kelvin), pressure)
+
+	numerator := rd*temperatureK + lv*ws
+	denominator := cpd + lv*lv*ws*epsilon/(rd*temperatureK*temperatureK)
+
+	return numerator / denominator / pressure
+}
+
+// Follows a moist adiabat from (pressure, temperatureK) to target pressure
+func moistAdiabat(temperatureK, pressure, target float64) float64 {
+	const maxStep = 2.0 // [hPa]
+
+	steps := int(math.Ceil(math.Abs(pressure-target) / maxStep))
+
+	if steps == 0 {
+		return temperatureK
+	}
+
+	dp := (target - pressure) / float64(steps)
+
+	for i := 0; i < steps; i++ {
+		k1 := moistLapseRate(temperatureK, pressure)
+		k2 := moistLapseRate(temperatureK+k1*dp/2, pressure+dp/2)
+		k3 := moistLapseRate(temperatureK+k2*dp/2, pressure+dp/2)
+		k4 := moistLapseRate(temperatureK+k3*dp, pressure+dp)
+
+		temperatureK += dp * (k1 + 2*k2 + 2*k3 + k4) / 6
+		pressure += dp
+	}
+
+	return temperatureK
+}
+
+// Lifting condensation level temperature [K] and pressure [hPa] (Bolton 1980)
+func liftingCondensationLevel(temperatureC, dewPointC, pressure float64) (float64, float64) {
+	t := temperatureC + kelvin
+	td := dewPointC + kelvin
+
+	tLCL := 1/(1/(td-56)+math.Log(t/td)/800) + 56
+	pLCL := pressure * math.Pow(tLCL/t, 1/kappa)
+
+	return tLCL, pLCL
+}
+
+// parcel is a surface based parcel lifted through a sounding
+type parcel struct {
+	pressure     float64
+	temperatureK float64
+	mixingRatio  float64
+	lclK         float64
+	lclPressure  float64
+}
+
+func newSurfaceParcel(surface thermoLevel) (*parcel, bool) {
+	if math.IsNaN(surface.dewPointC) {
+		return nil, false
+	}
+
+	tLCL, pLCL := liftingCondensationLevel(surface.temperatureC, surface.dewPointC, surface.pressure)
+
+	return &parcel{
+		pressure:     surface.pressure,
+		temperatureK: surface.temperatureC + kelvin,
+		mixingRatio:  mixingRatio(saturationVaporPressure(surface.dewPointC), surface.pressure),
+		lclK:         tLCL,
+		lclPressure:  pLCL,
+	}, true
+}
+
+// Parcel temperature [K] at a pressure
+func (p *parcel) temperatureAt(pressure float64) float64 {
+	if pressure >= p.lclPressure {
+		return p.temperatureK * math.Pow(pressure/p.pressure, kappa)
+	}
+
+	return moistAdiabat(p.lclK, p.lclPressure, pressure)
+}
+
+// Parcel virtual temperature [K] at a pressure
+func (p *parcel) virtualTemperatureAt(pressure float64) float64 {
+	t := p.temperatureAt(pressure)
+
+	if pressure >= p.lclPressure {
+		return virtualTemperature(t, p.mixingRatio)
+	}
+
+	return virtualTemperature(t, mixingRatio(saturationVaporPressure(t-kelvin), pressure))
+}
+
+func (l thermoLevel) virtualTemperature() float64 {
+	if math.IsNaN(l.dewPointC) {
+		return l.temperatureC + kelvin
+	}
+
+	return virtualTemperature(l.temperatureC+kelvin, mixingRatio(saturationVaporPressure(l.dewPointC), l.pressure))
+}
+
+// LiftedIndex is the 500 hPa environmental temperature minus the temperature
+// of a surface parcel lifted to 500 hPa [C]. False when the sounding does not
+// reach 500 hPa or has no surface dew point
+func (p *RAOBProfile) LiftedIndex() (float64, bool) {
+	levels := p.thermoLevels()
+
+	if len(levels) < 2 {
+		return 0, false
+	}
+
+	parcel, ok := newSurfaceParcel(levels[0])
+
+	if !ok {
+		return 0, false
+	}
+
+	for i := 1; i < len(levels); i++ {
+		below, above := levels[i-1], levels[i]
+
+		if above.pressure > 500 {
+			continue
+		}
+
+		// Interpolate the environment linearly in ln(p)
+		f := math.Log(below.pressure/500) / math.Log(below.pressure/above.pressure)
+		environment := below.temperatureC + f*(above.temperatureC-below.temperatureC)
+
+		return environment - (parcel.temperatureAt(500) - kelvin), true
+	}
+
+	return 0, false
+}
+
+// CAPE returns the convective available potential energy and convective
+// inhibition [J/kg] of a surface based parcel using virtual temperature.
+// CAPE is the positive area above the level of free convection and CIN is
+// the negative area below it, reported as a value <= 0. Both are 0 when the
+// parcel has no level of free convection
+func (p *RAOBProfile) CAPE() (cape float64, cin float64, ok bool) {
+	levels := p.thermoLevels()
+
+	if len(levels) < 2 {
+		return 0, 0, false
+	}
+
+	parcel, ok := newSurfaceParcel(levels[0])
+
+	if !ok {
+		return 0, 0, false
+	}
+
+	buoyancy := make([]float64, len(levels))
+
+	for i, level := range levels {
+		environment := level.virtualTemperature()
+		buoyancy[i] = gravity * (parcel.virtualTemperatureAt(level.pressure) - environment) / environment
+	}
+
+	negative := 0.0
+	reachedLFC := false
+
+	for i := 1; i < len(levels); i++ {
+		dz := levels[i].height - levels[i-1].height
+		b1, b2 := buoyancy[i-1], buoyancy[i]
+
+		var positiveArea, negativeArea float64
+
+		switch {
+		case b1 >= 0 && b2 >= 0:
+			positiveArea = (b1 + b2) / 2 * dz
+		case b1 <= 0 && b2 <= 0:
+			negativeArea = (b1 + b2) / 2 * dz
+		default:
+			// Split the layer where the buoyancy changes sign
+			zero := dz * b1 / (b1 - b2)
+
+			if b1 > 0 {
+				positiveArea = b1 / 2 * zero
+				negativeArea = b2 / 2 * (dz - zero)
+			} else {
+				negativeArea = b1 / 2 * zero
+				positiveArea = b2 / 2 * (dz - zero)
+			}
+		}
+
+		if positiveArea > 0 && levels[i].pressure <= parcel.lclPressure {
+			reachedLFC = true
+		}
+
+		if reachedLFC {
+			cape += positiveArea
+		} else {
+			negative += negativeArea
+		}
+	}
+
+	// Without a level of free convection the parcel never rises on its own
+	if !reachedLFC {
+		return 0, 0, true
+	}
+
+	return cape, negative, true
+}
+
+// PrecipitableWater is the total water vapor in the column [mm]
+func (p *RAOBProfile) PrecipitableWater() (float64, bool) {
+	levels := []thermoLevel{}
+
+	for _, level := range p.thermoLevels() {
+		if !math.IsNaN(level.dewPointC) {
+			levels = append(levels, level)
+		}
+	}
+
+	if len(levels) < 2 {
+		return 0, false
+	}
+
+	specificHumidity := func(level thermoLevel) float64 {
+		w := mixingRatio(saturationVaporPressure(level.dewPointC), level.pressure)
+		return w / (1 + w)
+	}
+
+	total := 0.0
+
+	for i := 1; i < len(levels); i++ {
+		dp := (levels[i-1].pressure - levels[i].pressure) * 100 // [Pa]
+		total += (specificHumidity(levels[i-1]) + specificHumidity(levels[i])) / 2 * dp
+	}
+
+	// kg/m^2 of water is equivalent to mm
+	return total / gravity, true
+}
+
+// FreezingLevel is the lowest height [m] where the temperature crosses 0C.
+// When the surface is at or below freezing the surface height is returned
+func (p *RAOBProfile) FreezingLevel() (float64, bool) {
+	levels := p.thermoLevels()
+
+	if len(levels) == 0 {
+		return 0, false
+	}
+
+	if levels[0].temperatureC <= 0 {
+		return levels[0].height, true
+	}
+
+	for i := 1; i < len(levels); i++ {
+		below, above := levels[i-1], levels[i]
+
+		if above.temperatureC > 0 {
+			continue
+		}
+
+		f := below.temperatureC / (below.temperatureC - above.temperatureC)
+
+		return below.height + f*(above.height-below.height), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,96 @@
+package iem
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+)
+
+func loadRAOBFixture(t *testing.T, path string) *RAOBProfile {
+	t.Helper()
+
+	body, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raobResponse iemRAOBJsonResponse
+
+	if err := json.Unmarshal(body, &raobResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	return raobResponse.Profiles[0]
+}
+
+func TestRAOBUnstableSounding(t *testing.T) {
+	profile := loadRAOBFixture(t, "./data/raob_koax_summer.json")
+
+	li, ok := profile.LiftedIndex()
+
+	if !ok || li > -8 || li < -11 {
+		t.Errorf("lifted index = %f, want between -11 and -8", li)
+	}
+
+	cape, cin, ok := profile.CAPE()
+
+	if !ok || cape < 4000 || cape > 5200 {
+		t.Errorf("cape = %f, want between 4000 and 5200", cape)
+	}
+
+	if cin > -20 || cin < -100 {
+		t.Errorf("cin = %f, want between -100 and -20", cin)
+	}
+
+	pw, ok := profile.PrecipitableWater()
+
+	if !ok || pw < 33 || pw > 40 {
+		t.Errorf("precipitable water = %f, want between 33 and 40", pw)
+	}
+
+	// 2C at 4390m and -3C at 5100m
+	freezing, ok := profile.FreezingLevel()
+
+	if !ok || math.Abs(freezing-4674) > 0.01 {
+		t.Errorf("freezing level = %f, want 4674", freezing)
+	}
+}
+
+func TestRAOBStableSounding(t *testing.T) {
+	profile := loadRAOBFixture(t, "./data/raob_kabr_winter.json")
+
+	li, ok := profile.LiftedIndex()
+
+	if !ok || li < 8 || li > 14 {
+		t.Errorf("lifted index = %f, want between 8 and 14", li)
+	}
+
+	cape, cin, ok := profile.CAPE()
+
+	if !ok || cape != 0 || cin != 0 {
+		t.Errorf("cape, cin = %f, %f, want 0, 0", cape, cin)
+	}
+
+	pw, ok := profile.PrecipitableWater()
+
+	if !ok || pw < 1.5 || pw > 4 {
+		t.Errorf("precipitable water = %f, want between 1.5 and 4", pw)
+	}
+
+	freezing, ok := profile.FreezingLevel()
+
+	if !ok || freezing != 397 {
+		t.Errorf("freezing level = %f, want surface height 397", freezing)
+	}
+}
+
+func TestMoistAdiabat(t *testing.T) {
+	// A saturated parcel at 1000 hPa and 20C is about -8.5C at 500 hPa
+	temperature := moistAdiabat(20+kelvin, 1000, 500) - kelvin
+
+	if math.Abs(temperature+8.5) > 1 {
+		t.Errorf("moist adiabat temperature = %f, want about -8.5", temperature)
+	}
+}
@@ -0,0 +1,236 @@
+package iem
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type ResampleInterval int
+
+const (
+	ResampleHourly ResampleInterval = iota
+	ResampleDaily
+)
+
+// ResampleStats summarizes the observed values of a variable in a period.
+// Properties are nil when nothing was observed
+type ResampleStats struct {
+	Count int      `json:"count"`
+	Mean  *float64 `json:"mean,omitempty"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+func (s *ResampleStats) add(value float64) {
+	if s.Count == 0 {
+		s.Mean, s.Min, s.Max = new(float64), new(float64), new(float64)
+		*s.Min, *s.Max = value, value
+	}
+
+	s.Count++
+	*s.Mean += (value - *s.Mean) / float64(s.Count)
+	*s.Min = math.Min(*s.Min, value)
+	*s.Max = math.Max(*s.Max, value)
+}
+
+// ResampledWeatherData summarizes a station's observations in a regular period
+type ResampledWeatherData struct {
+	Station      string    `json:"station"`
+	Start        time.Time `json:"start"` // Start of the period (inclusive)
+	End          time.Time `json:"end"`   // End of the period (exclusive)
+	Observations int       `json:"observations"`
+
+	TemperatureF     ResampleStats `json:"tmpf"` // Air Temperature [F]
+	DewPointF        ResampleStats `json:"dwpf"` // Dew Point [F]
+	RelativeHumidity ResampleStats `json:"relh"` // Relative Humidity [%]
+
+	WindSpeedKnots ResampleStats `json:"sknt"`           // Wind Speed [knots]
+	WindDirection  *float64      `json:"drct,omitempty"` // Vector averaged Wind Direction [deg]
+	WindGustKnots  *float64      `json:"gust,omitempty"` // Highest Wind Gust [knots]
+
+	SeaLevelPressure ResampleStats `json:"mslp"` // Sea Level Pressure [mb]
+
+	PrecipInch float64 `json:"p01i"` // Precipitation [inch]
+
+	// Wind vector sums used for the vector averaged direction
+	u, v float64
+}
+
+// ResampleWeatherData groups each station's observations into regular hourly or
+// daily periods. Periods start on the hour or at midnight in the query's
+// timezone, a nil query uses UTC. Only periods with observations are returned,
+// ordered by station then time.
+//
+// Missing values are not included in the stats.
+//
+// Precipitation (p01i) accumulates from one routine hourly report to the next,
+// specials in between report the amount so far. Each station's routine report
+// minute is detected as the most common minute of its observations and the
+// largest amount reported in each routine period is added to the period the
+// routine report falls in, or the period of the period's last observation when
+// the routine report is missing
+func ResampleWeatherData(data []*IEMWeatherData, interval ResampleInterval, query *WeatherDataQueryBuilder) []*ResampledWeatherData {
+	location := time.UTC
+
+	if query != nil {
+		location = query.Location()
+	}
+
+	stations := map[string][]*IEMWeatherData{}
+
+	for _, d := range data {
+		if d.Time == nil {
+			continue
+		}
+
+		stations[d.Station] = append(stations[d.Station], d)
+	}
+
+	resampled := []*ResampledWeatherData{}
+
+	for station, observations := range stations {
+		sort.SliceStable(observations, func(i, j int) bool {
+			return observations[i].Time.Before(*observations[j].Time)
+		})
+
+		resampled = append(resampled, resampleStation(station, observations, interval, location)...)
+	}
+
+	sort.Slice(resampled, func(i, j int) bool {
+		if resampled[i].Station != resampled[j].Station {
+			return resampled[i].Station < resampled[j].Station
+		}
+
+		return resampled[i].Start.Before(resampled[j].Start)
+	})
+
+	return resampled
+}
+
+func resampleStation(station string, observations []*IEMWeatherData, interval ResampleInterval, location *time.Location) []*ResampledWeatherData {
+	periods := []*ResampledWeatherData{}
+	byStart := map[time.Time]*ResampledWeatherData{}
+
+	period := func(t time.Time) *ResampledWeatherData {
+		start, end := resamplePeriod(t.In(location), interval)
+
+		if p, ok := byStart[start]; ok {
+			return p
+		}
+
+		p := &ResampledWeatherData{Station: station, Start: start, End: end}
+		byStart[start] = p
+		periods = append(periods, p)
+
+		return p
+	}
+
+	for _, d := range observations {
+		p := period(*d.Time)
+		p.Observations++
+
+		addObserved(&p.TemperatureF, d, TempF)
+		addObserved(&p.DewPointF, d, DewPointF)
+		addObserved(&p.RelativeHumidity, d, RelativeHumidity)
+		addObserved(&p.SeaLevelPressure, d, SeaLevelPressure)
+		addObserved(&p.WindSpeedKnots, d, WindSpeedKnots)
+
+		// Calm winds have no speed so they do not change the vector average
+		if !d.IsMissing(WindDirection) && !d.IsMissing(WindSpeedKnots) {
+			radians := d.WindDirection * math.Pi / 180
+			p.u += -d.WindSpeedKnots * math.Sin(radians)
+			p.v += -d.WindSpeedKnots * math.Cos(radians)
+		}
+
+		if !d.IsMissing(WindGustKnots) && (p.WindGustKnots == nil || d.WindGustKnots > *p.WindGustKnots) {
+			gust := d.WindGustKnots
+			p.WindGustKnots = &gust
+		}
+	}
+
+	for _, precip := range routinePrecip(observations) {
+		period(precip.at).PrecipInch += precip.inch
+	}
+
+	for _, p := range periods {
+		if p.u != 0 || p.v != 0 {
+			direction := math.Mod(math.Atan2(-p.u, -p.v)*180/math.Pi+360, 360)
+
+			if direction == 0 {
+				direction = 360
+			}
+
+			p.WindDirection = &direction
+		}
+	}
+
+	return periods
+}
+
+// Adds the value of a column to stats when it is not missing
+func addObserved(stats *ResampleStats, d *IEMWeatherData, column WeatherDataData) {
+	if d.IsMissing(column) {
+		return
+	}
+
+	value, _ := d.Float(column)
+	stats.add(value)
+}
+
+func resamplePeriod(t time.Time, interval ResampleInterval) (time.Time, time.Time) {
+	if interval == ResampleDaily {
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, 1)
+	}
+
+	start := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	return start, start.Add(time.Hour)
+}
+
+type periodPrecip struct {
+	inch float64
+	at   time.Time // Time of the last observation in the period
+}
+
+// Precipitation of each routine period keyed by the time of the routine report
+// ending it. Observations must be sorted by time
+func routinePrecip(observations []*IEMWeatherData) map[time.Time]*periodPrecip {
+	minutes := map[int]int{}
+	routineMinute := 0
+
+	for _, d := range observations {
+		minute := d.Time.UTC().Minute()
+		minutes[minute]++
+
+		if minutes[minute] > minutes[routineMinute] {
+			routineMinute = minute
+		}
+	}
+
+	precip := map[time.Time]*periodPrecip{}
+
+	for _, d := range observations {
+		t := d.Time.UTC()
+		routine := t.Truncate(time.Hour).Add(time.Duration(routineMinute) * time.Minute)
+
+		if t.After(routine) {
+			routine = routine.Add(time.Hour)
+		}
+
+		p, ok := precip[routine]
+
+		if !ok {
+			p = &periodPrecip{}
+			precip[routine] = p
+		}
+
+		if !d.IsMissing(PrecipInch) {
+			p.inch = math.Max(p.inch, d.PrecipInch)
+		}
+
+		p.at = *d.Time
+	}
+
+	return precip
+}
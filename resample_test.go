@@ -0,0 +1,118 @@
+package iem
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseWeatherDataFile(t *testing.T, name string, query *WeatherDataQueryBuilder) []*IEMWeatherData {
+	t.Helper()
+
+	file, err := os.Open(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	data, err := ParseWeatherData(file, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestResampleWeatherDataHourly(t *testing.T) {
+	query := NewWeatherDataQuery()
+	data := parseWeatherDataFile(t, "data/full_weather_data.csv", query)
+
+	hours := ResampleWeatherData(data, ResampleHourly, query)
+
+	if len(hours) != 24 {
+		t.Fatalf("expected 24 hours, got %d", len(hours))
+	}
+
+	rain := hours[1]
+
+	if rain.Observations != 6 || math.Abs(rain.PrecipInch-0.61) > 1e-9 {
+		t.Errorf("unexpected 01Z hour %d obs %f precip", rain.Observations, rain.PrecipInch)
+	}
+
+	if *rain.TemperatureF.Max != 68 || *rain.TemperatureF.Min > *rain.TemperatureF.Mean {
+		t.Errorf("unexpected 01Z temperatures %+v", rain.TemperatureF)
+	}
+
+	if rain.WindDirection == nil || *rain.WindDirection < 250 || *rain.WindDirection > 300 {
+		t.Errorf("unexpected 01Z wind direction %v", rain.WindDirection)
+	}
+
+	if math.Abs(hours[2].PrecipInch-0.10) > 1e-9 {
+		t.Errorf("expected 0.10 precip at 02Z, got %f", hours[2].PrecipInch)
+	}
+}
+
+func TestResampleWeatherDataDailyTimezone(t *testing.T) {
+	query := NewWeatherDataQuery().Timezone("America/Chicago")
+	data := parseWeatherDataFile(t, "data/full_weather_data.csv", query)
+
+	if !data[0].Time.Equal(time.Date(2023, 10, 4, 5, 54, 0, 0, time.UTC)) {
+		t.Fatalf("expected times parsed in America/Chicago, got %v", data[0].Time)
+	}
+
+	days := ResampleWeatherData(data, ResampleDaily, query)
+
+	if len(days) != 1 {
+		t.Fatalf("expected 1 day, got %d", len(days))
+	}
+
+	if days[0].Start.Day() != 4 || days[0].Start.Hour() != 0 || days[0].Start.Location().String() != "America/Chicago" {
+		t.Errorf("unexpected day start %v", days[0].Start)
+	}
+
+	if math.Abs(days[0].PrecipInch-0.71) > 1e-9 {
+		t.Errorf("expected 0.71 precip, got %f", days[0].PrecipInch)
+	}
+}
+
+func TestResampleWeatherDataMissingAndZero(t *testing.T) {
+	query := NewWeatherDataQuery()
+	body := "station,valid,tmpf,dwpf,drct,sknt,gust\n" +
+		"LNK,2023-01-04 06:14,0.00,-8.00,M,10.00,M\n" +
+		"LNK,2023-01-04 06:54,2.00,0.00,360.00,M,M\n"
+
+	data, err := ParseWeatherData(strings.NewReader(body), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hours := ResampleWeatherData(data, ResampleHourly, query)
+
+	if len(hours) != 1 {
+		t.Fatalf("expected 1 hour, got %d", len(hours))
+	}
+
+	hour := hours[0]
+
+	if hour.TemperatureF.Count != 2 || *hour.TemperatureF.Min != 0 || *hour.TemperatureF.Mean != 1 {
+		t.Errorf("expected 0F to be included, got %+v", hour.TemperatureF)
+	}
+
+	if hour.DewPointF.Count != 2 || *hour.DewPointF.Max != 0 {
+		t.Errorf("expected a 0F dew point to be included, got %+v", hour.DewPointF)
+	}
+
+	if hour.WindSpeedKnots.Count != 1 || *hour.WindSpeedKnots.Mean != 10 {
+		t.Errorf("expected missing wind speeds to be skipped, got %+v", hour.WindSpeedKnots)
+	}
+
+	if hour.WindDirection != nil || hour.WindGustKnots != nil {
+		t.Errorf("expected no wind direction or gust, got %v %v", hour.WindDirection, hour.WindGustKnots)
+	}
+}
@@ -0,0 +1,390 @@
+package iem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RWISVariable string
+
+const (
+	RWISTempF              RWISVariable = "tmpf"      // Air Temperature [F]
+	RWISDewPointF          RWISVariable = "dwpf"      // Dew Point [F]
+	RWISRelativeHumidity   RWISVariable = "relh"      // Relative Humidity [%]
+	RWISFeelsLikeF         RWISVariable = "feel"      // Feels Like Temperature [F]
+	RWISWindDirection      RWISVariable = "drct"      // Wind Direction [deg]
+	RWISWindSpeedKnots     RWISVariable = "sknt"      // Wind Speed [knots]
+	RWISWindGustKnots      RWISVariable = "gust"      // Wind Gust [knots]
+	RWISVisibilityMiles    RWISVariable = "vsby"      // Visibility [miles]
+	RWISPrecipInch         RWISVariable = "pcpn"      // Precipitation [inch]
+	RWISSubsurfaceTempF    RWISVariable = "subf"      // Subsurface Temperature [F]
+	RWISPavementTemp0      RWISVariable = "tfs0"      // Pavement Sensor 0 Temperature [F]
+	RWISPavementTemp1      RWISVariable = "tfs1"      // Pavement Sensor 1 Temperature [F]
+	RWISPavementTemp2      RWISVariable = "tfs2"      // Pavement Sensor 2 Temperature [F]
+	RWISPavementTemp3      RWISVariable = "tfs3"      // Pavement Sensor 3 Temperature [F]
+	RWISPavementCondition0 RWISVariable = "tfs0_text" // Pavement Sensor 0 Condition
+	RWISPavementCondition1 RWISVariable = "tfs1_text" // Pavement Sensor 1 Condition
+	RWISPavementCondition2 RWISVariable = "tfs2_text" // Pavement Sensor 2 Condition
+	RWISPavementCondition3 RWISVariable = "tfs3_text" // Pavement Sensor 3 Condition
+)
+
+// Number of pavement sensors reported by an RWIS station (tfs0 - tfs3)
+const rwisPavementSensors = 4
+
+// RWISNetwork is the IEM network id of a state's RWIS stations (IA -> IA_RWIS)
+func RWISNetwork(state string) string {
+	return fmt.Sprintf("%s_RWIS", strings.ToUpper(state))
+}
+
+// RWISPavementSensor is a reading of one pavement sensor at an RWIS station.
+// Properties are present depending on the query used to fetch the data
+type RWISPavementSensor struct {
+	Sensor       int     `json:"sensor"`              // Sensor number (0 - 3)
+	TemperatureF float64 `json:"tmpf,omitempty"`      // Pavement Temperature [F] (tfsN)
+	Condition    string  `json:"condition,omitempty"` // Pavement Condition, Dry, Wet, etc (tfsN_text)
+}
+
+// RWISData represents an observation at a Road Weather Information System station
+// Properties are present depending on the query used to fetch the data
+type RWISData struct {
+	Station string `json:"station"` // Station recorded at (station)
+
+	Time *time.Time `json:"time"` // Time recorded at (valid)
+
+	TemperatureF     float64 `json:"tmpf,omitempty"` // Air Temperature [F] (tmpf)
+	DewPointF        float64 `json:"dwpf,omitempty"` // Dew Point [F] (dwpf)
+	RelativeHumidity float64 `json:"relh,omitempty"` // Relative Humidity [%] (relh)
+	FeelsLikeF       float64 `json:"feel,omitempty"` // Feels Like Temperature [F] (feel)
+
+	WindDirection  float64 `json:"drct,omitempty"` // Wind Direction [deg] (drct)
+	WindSpeedKnots float64 `json:"sknt,omitempty"` // Wind Speed [knots] (sknt)
+	WindGustKnots  float64 `json:"gust,omitempty"` // Wind Gust [knots] (gust)
+
+	VisibilityMiles float64 `json:"vsby,omitempty"` // Visibility [miles] (vsby)
+	PrecipInch      float64 `json:"pcpn,omitempty"` // Precipitation [inch] (pcpn)
+
+	SubsurfaceTemperatureF float64 `json:"subf,omitempty"` // Subsurface Temperature [F] (subf)
+
+	// Pavement sensors with a temperature or condition in the observation
+	Pavement []*RWISPavementSensor `json:"pavement,omitempty"`
+
+	// Variables that were observed or missing. Bits are the variables'
+	// indexes in rwisVariables
+	state columnState
+}
+
+// Numeric variables parsed into RWISData
+var rwisVariables = []RWISVariable{
+	RWISTempF, RWISDewPointF, RWISRelativeHumidity, RWISFeelsLikeF, RWISWindDirection,
+	RWISWindSpeedKnots, RWISWindGustKnots, RWISVisibilityMiles, RWISPrecipInch,
+	RWISSubsurfaceTempF, RWISPavementTemp0, RWISPavementTemp1, RWISPavementTemp2,
+	RWISPavementTemp3,
+}
+
+// Variables where 0 is a routinely observed value
+var rwisZeroObserved = map[RWISVariable]bool{
+	RWISWindDirection:  true,
+	RWISWindSpeedKnots: true,
+	RWISWindGustKnots:  true,
+	RWISPrecipInch:     true,
+}
+
+var rwisPavementTemps = []RWISVariable{RWISPavementTemp0, RWISPavementTemp1, RWISPavementTemp2, RWISPavementTemp3}
+
+func (d *RWISData) field(variable RWISVariable) *float64 {
+	switch variable {
+	case RWISTempF:
+		return &d.TemperatureF
+	case RWISDewPointF:
+		return &d.DewPointF
+	case RWISRelativeHumidity:
+		return &d.RelativeHumidity
+	case RWISFeelsLikeF:
+		return &d.FeelsLikeF
+	case RWISWindDirection:
+		return &d.WindDirection
+	case RWISWindSpeedKnots:
+		return &d.WindSpeedKnots
+	case RWISWindGustKnots:
+		return &d.WindGustKnots
+	case RWISVisibilityMiles:
+		return &d.VisibilityMiles
+	case RWISPrecipInch:
+		return &d.PrecipInch
+	case RWISSubsurfaceTempF:
+		return &d.SubsurfaceTemperatureF
+	}
+
+	if sensor, ok := variableIndex(rwisPavementTemps, variable); ok {
+		for _, p := range d.Pavement {
+			if p.Sensor == sensor {
+				return &p.TemperatureF
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsMissing reports whether a numeric variable is missing. Parsed data knows
+// which of its variables were missing or not in the response. Otherwise, as
+// for data created by hand, zero values are missing except for wind and
+// precipitation, which are routinely 0
+func (d *RWISData) IsMissing(variable RWISVariable) bool {
+	if i, ok := variableIndex(rwisVariables, variable); ok {
+		if missing, known := d.state.isMissing(i); known {
+			return missing
+		}
+	}
+
+	field := d.field(variable)
+
+	if field == nil {
+		return true
+	}
+
+	return *field == 0 && !rwisZeroObserved[variable]
+}
+
+// RWISQueryBuilder represents the url query sent to the IEM API
+// when requesting RWIS data for stations
+type RWISQueryBuilder struct {
+	// Network the stations belong to (IA_RWIS)
+	network string
+
+	// Stations to get data from (RAME)
+	stations []string
+
+	// List of variables requested from IEM API
+	data []RWISVariable
+
+	// Start date to query for
+	start time.Time
+
+	// End date to query for
+	end time.Time
+
+	// Timezone used for dates
+	tz string
+
+	// Location of tz, loaded when tz is set
+	location *time.Location
+
+	// How missing data is represented
+	missing WeatherDataQueryMissing
+}
+
+// Creates a new RWISQueryBuilder with defaults set to optional fields
+func NewRWISQuery() *RWISQueryBuilder {
+	return &RWISQueryBuilder{
+		tz:       defaultTimeZone,
+		location: loadTimezone(defaultTimeZone),
+		missing:  defaultMissing,
+
+		start: time.Now(),
+		end:   time.Now(),
+	}
+}
+
+func (b *RWISQueryBuilder) isMissingOrTrace(value string) bool {
+	return value == b.missing.text()
+}
+
+// Sets query builder network (IA_RWIS)
+func (b *RWISQueryBuilder) Network(network string) *RWISQueryBuilder {
+	b.network = network
+	return b
+}
+
+// Appends stations to builder.station
+func (b *RWISQueryBuilder) Stations(stations ...string) *RWISQueryBuilder {
+	b.stations = append(b.stations, stations...)
+	return b
+}
+
+// Appends variables to builder.data
+func (b *RWISQueryBuilder) Data(data ...RWISVariable) *RWISQueryBuilder {
+	b.data = append(b.data, data...)
+	return b
+}
+
+// Sets query builder start date (defaults to today)
+func (b *RWISQueryBuilder) Start(t time.Time) *RWISQueryBuilder {
+	b.start = t
+	return b
+}
+
+// Sets query builder end date (defaults to today)
+func (b *RWISQueryBuilder) End(t time.Time) *RWISQueryBuilder {
+	b.end = t
+	return b
+}
+
+// Sets query builder timezone (defaults to Etc/UTC)
+func (b *RWISQueryBuilder) Timezone(tz string) *RWISQueryBuilder {
+	b.tz = tz
+	b.location = loadTimezone(tz)
+	return b
+}
+
+// Location of the query's timezone that returned times are parsed in.
+// UTC when the timezone is not known
+func (b *RWISQueryBuilder) Location() *time.Location {
+	if b.location == nil {
+		return loadTimezone(b.tz)
+	}
+
+	return b.location
+}
+
+// Sets query builder missing property (defaults to M)
+func (b *RWISQueryBuilder) Missing(missing WeatherDataQueryMissing) *RWISQueryBuilder {
+	b.missing = missing
+	return b
+}
+
+// Creates url.Values with validated data from query builder
+func (b *RWISQueryBuilder) BuildUrl() (url.Values, error) {
+	v := url.Values{}
+
+	if b.network == "" {
+		return nil, builderRequiredError("RWISQueryBuilder", "network")
+	}
+
+	v.Add("network", b.network)
+
+	if b.stations == nil {
+		return nil, builderRequiredError("RWISQueryBuilder", "stations")
+	}
+
+	for _, s := range b.stations {
+		v.Add("stations", s)
+	}
+
+	if b.data == nil {
+		return nil, builderRequiredError("RWISQueryBuilder", "data")
+	}
+
+	for _, d := range b.data {
+		v.Add("vars", string(d))
+	}
+
+	v.Add("year1", strconv.Itoa(b.start.Year()))
+	v.Add("month1", strconv.Itoa(int(b.start.Month())))
+	v.Add("day1", strconv.Itoa(b.start.Day()))
+	v.Add("hour1", strconv.Itoa(b.start.Hour()))
+	v.Add("minute1", strconv.Itoa(b.start.Minute()))
+
+	v.Add("year2", strconv.Itoa(b.end.Year()))
+	v.Add("month2", strconv.Itoa(int(b.end.Month())))
+	v.Add("day2", strconv.Itoa(b.end.Day()))
+	v.Add("hour2", strconv.Itoa(b.end.Hour()))
+	v.Add("minute2", strconv.Itoa(b.end.Minute()))
+
+	v.Add("tz", b.tz)
+	v.Add("delim", "comma")
+	v.Add("missing", string(b.missing))
+	v.Add("what", "view")
+
+	return v, nil
+}
+
+type RWISService interface {
+	Get(ctx context.Context, query *RWISQueryBuilder) ([]*RWISData, error)
+
+	// Gets the RWIS stations of a state (IA)
+	GetStations(ctx context.Context, state string) ([]*Station, error)
+}
+
+type IEMRWISService struct {
+	client *Client
+}
+
+func (s *IEMRWISService) Get(ctx context.Context, query *RWISQueryBuilder) ([]*RWISData, error) {
+	v, err := query.BuildUrl()
+
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("/cgi-bin/request/rwis.py?%s", v.Encode())
+
+	var data []*RWISData
+
+	err = s.client.get(ctx, url, func(body io.Reader) (err error) {
+		data, err = ParseRWISData(body, query)
+		return err
+	})
+
+	return data, err
+}
+
+func (s *IEMRWISService) GetStations(ctx context.Context, state string) ([]*Station, error) {
+	return s.client.Stations().GetStations(ctx, RWISNetwork(state))
+}
+
+// Parse RWIS data from a io.Reader that reads CSV data based on a RWISQueryBuilder
+func ParseRWISData(reader io.Reader, query *RWISQueryBuilder) ([]*RWISData, error) {
+	data := []*RWISData{}
+
+	err := readCsvRecords(reader, func(w *weatherDataIndecies, record *[]string) error {
+		d := &RWISData{}
+
+		err := errors.Join(
+			w.setString("station", record, &d.Station, query),
+			w.setTime("valid", record, &d.Time, query),
+			w.setFloat("tmpf", record, &d.TemperatureF, query),
+			w.setFloat("dwpf", record, &d.DewPointF, query),
+			w.setFloat("relh", record, &d.RelativeHumidity, query),
+			w.setFloat("feel", record, &d.FeelsLikeF, query),
+			w.setFloat("drct", record, &d.WindDirection, query),
+			w.setFloat("sknt", record, &d.WindSpeedKnots, query),
+			w.setFloat("gust", record, &d.WindGustKnots, query),
+			w.setFloat("vsby", record, &d.VisibilityMiles, query),
+			w.setFloat("pcpn", record, &d.PrecipInch, query),
+			w.setFloat("subf", record, &d.SubsurfaceTemperatureF, query),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		for i, v := range rwisVariables {
+			d.state.parse(i, string(v), w, record, query)
+		}
+
+		for i := 0; i < rwisPavementSensors; i++ {
+			sensor := &RWISPavementSensor{Sensor: i}
+			column := fmt.Sprintf("tfs%d", i)
+
+			err := errors.Join(
+				w.setFloat(column, record, &sensor.TemperatureF, query),
+				w.setString(column+"_text", record, &sensor.Condition, query),
+			)
+
+			if err != nil {
+				return err
+			}
+
+			if !d.IsMissing(rwisPavementTemps[i]) || sensor.Condition != "" {
+				d.Pavement = append(d.Pavement, sensor)
+			}
+		}
+
+		data = append(data, d)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,89 @@
+package iem
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRWISData(t *testing.T) {
+	file, err := os.Open("./data/rwis_ia.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	query := NewRWISQuery().Timezone("America/Chicago")
+	data, err := ParseRWISData(file, query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 3 {
+		t.Fatalf("expected 3 observations, got %d", len(data))
+	}
+
+	d := data[1]
+	valid := time.Date(2023, 1, 4, 6, 10, 0, 0, query.Location())
+
+	if d.Station != "RAME" || !d.Time.Equal(valid) {
+		t.Errorf("unexpected station and time %s %s", d.Station, d.Time)
+	}
+
+	if d.TemperatureF != 21 || d.FeelsLikeF != 11.40 || d.WindGustKnots != 17 || d.VisibilityMiles != 1.75 || d.PrecipInch != 0.01 || d.SubsurfaceTemperatureF != 33.80 {
+		t.Errorf("unexpected values %+v", d)
+	}
+
+	// Sensors without a temperature or condition are left out
+	if len(d.Pavement) != 2 {
+		t.Fatalf("expected 2 pavement sensors, got %d", len(d.Pavement))
+	}
+
+	if d.Pavement[0].Sensor != 0 || d.Pavement[0].TemperatureF != 23.90 || d.Pavement[0].Condition != "Ice Watch" {
+		t.Errorf("unexpected sensor %+v", d.Pavement[0])
+	}
+
+	if d.Pavement[1].Sensor != 1 || d.Pavement[1].Condition != "Ice Warning" {
+		t.Errorf("unexpected sensor %+v", d.Pavement[1])
+	}
+
+	// Sensors are numbered by their column, not their position
+	dsm := data[2]
+
+	if len(dsm.Pavement) != 2 || dsm.Pavement[1].Sensor != 2 || dsm.Pavement[1].TemperatureF != 25.10 {
+		t.Errorf("unexpected sensors %+v", dsm.Pavement)
+	}
+
+	if !dsm.IsMissing(RWISWindGustKnots) || !dsm.IsMissing(RWISPrecipInch) || !dsm.IsMissing(RWISPavementTemp1) {
+		t.Errorf("expected missing values %+v", dsm)
+	}
+
+	// pcpn of 0.00 is observed
+	if data[0].IsMissing(RWISPrecipInch) || data[0].IsMissing(RWISPavementTemp0) {
+		t.Errorf("expected observed values %+v", data[0])
+	}
+}
+
+func TestParseRWISDataMissingEmpty(t *testing.T) {
+	body := "station,valid,tmpf,pcpn,tfs0,tfs0_text\nRAME,2023-01-04 06:00,,0.00,0.00,\n"
+	data, err := ParseRWISData(strings.NewReader(body), NewRWISQuery().Missing(MissingEmpty))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := data[0]
+
+	if !d.IsMissing(RWISTempF) || d.IsMissing(RWISPrecipInch) {
+		t.Errorf("expected an empty tmpf to be missing %+v", d)
+	}
+
+	// A sensor reading 0F is kept
+	if len(d.Pavement) != 1 || d.IsMissing(RWISPavementTemp0) {
+		t.Errorf("expected a 0F pavement sensor %+v", d.Pavement)
+	}
+}
@@ -3,6 +3,7 @@ package iem
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 )
 
@@ -35,6 +36,17 @@ type StationService interface {
 	GetStations(ctx context.Context, networkId string) ([]*Station, error)
 }
 
+// StationGeoJSONGetter is implemented by station services that can get the
+// stations of a network from IEM's GeoJSON endpoint, such as IEMStationService
+//
+//	if getter, ok := client.Stations().(iem.StationGeoJSONGetter); ok {
+//		stations, err := getter.GetStationsGeoJSON(ctx, "IA_ASOS")
+//	}
+type StationGeoJSONGetter interface {
+	// Gets the stations of a network from /geojson/network/{id}.geojson
+	GetStationsGeoJSON(ctx context.Context, networkId string) ([]*Station, error)
+}
+
 type IEMStationService struct {
 	client *Client
 }
@@ -64,3 +76,15 @@ func (s *IEMStationService) GetStation(ctx context.Context, stationId string) (*
 
 	return stationResponse.Data[0], nil
 }
+
+func (s *IEMStationService) GetStationsGeoJSON(ctx context.Context, networkId string) ([]*Station, error) {
+	url := fmt.Sprintf("/geojson/network/%s.geojson", networkId)
+	var stations []*Station
+
+	err := s.client.get(ctx, url, func(body io.Reader) (err error) {
+		stations, err = ParseStationsGeoJSON(body)
+		return err
+	})
+
+	return stations, err
+}
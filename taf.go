@@ -0,0 +1,708 @@
+package iem
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TAFChangeType string
+
+const (
+	TAFBase     TAFChangeType = "BASE"  // Initial forecast conditions
+	TAFFrom     TAFChangeType = "FM"    // Rapid change to entirely new conditions
+	TAFTempo    TAFChangeType = "TEMPO" // Temporary fluctuations
+	TAFBecoming TAFChangeType = "BECMG" // Gradual change during a period
+	TAFProb     TAFChangeType = "PROB"  // Probability of conditions
+)
+
+type FlightCategory string
+
+const (
+	VFR  FlightCategory = "VFR"  // Ceiling > 3000ft and visibility > 5SM
+	MVFR FlightCategory = "MVFR" // Ceiling 1000-3000ft and/or visibility 3-5SM
+	IFR  FlightCategory = "IFR"  // Ceiling 500-999ft and/or visibility 1-2SM
+	LIFR FlightCategory = "LIFR" // Ceiling < 500ft and/or visibility < 1SM
+)
+
+// TAFWind is a forecast wind in the same units as IEMWeatherData
+type TAFWind struct {
+	Direction  float64 `json:"drct"`           // Wind Direction [deg], 0 when variable
+	Variable   bool    `json:"variable"`       // Variable wind direction (VRB)
+	SpeedKnots float64 `json:"sknt"`           // Wind Speed [knots]
+	GustKnots  float64 `json:"gust,omitempty"` // Wind Gust [knots]
+}
+
+// TAFVisibility is a forecast prevailing visibility
+type TAFVisibility struct {
+	Miles       float64 `json:"vsby"`         // Visibility [miles]
+	GreaterThan bool    `json:"greater_than"` // Visibility is greater than Miles (P6SM)
+}
+
+// TAFCloud is a forecast cloud layer
+type TAFCloud struct {
+	Coverage string  `json:"skyc"`           // FEW, SCT, BKN, OVC or VV
+	HeightFt float64 `json:"skyl"`           // Cloud base [ft]
+	Type     string  `json:"type,omitempty"` // CB or TCU
+}
+
+// TAFConditions are forecast conditions. Nil properties were not forecast
+// by a group and an empty Weather or Clouds slice is forecast as none
+// (NSW, SKC)
+type TAFConditions struct {
+	Wind       *TAFWind       `json:"wind,omitempty"`
+	Visibility *TAFVisibility `json:"visibility,omitempty"`
+	Weather    []string       `json:"wxcodes,omitempty"`
+	Clouds     []*TAFCloud    `json:"clouds,omitempty"`
+	WindShear  string         `json:"wind_shear,omitempty"` // Raw low level wind shear group (WS020/27045KT)
+}
+
+// TAFGroup is the base forecast or a change group of a TAF
+type TAFGroup struct {
+	TAFConditions
+
+	Type        TAFChangeType `json:"type"`
+	Probability int           `json:"probability,omitempty"` // PROB30, PROB40
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	Raw         string        `json:"raw"`
+}
+
+// Covers reports whether t falls within the group's period
+func (g *TAFGroup) Covers(t time.Time) bool {
+	return !t.Before(g.Start) && t.Before(g.End)
+}
+
+// TAF is a decoded terminal aerodrome forecast
+type TAF struct {
+	Station    string      `json:"station"`
+	Issued     time.Time   `json:"issued"`
+	ValidFrom  time.Time   `json:"valid_from"`
+	ValidTo    time.Time   `json:"valid_to"`
+	Amendment  bool        `json:"amendment"`
+	Correction bool        `json:"correction"`
+	Groups     []*TAFGroup `json:"groups"` // The base forecast followed by change groups
+	Raw        string      `json:"raw"`
+}
+
+// Covers reports whether t falls within the TAF's valid period
+func (t *TAF) Covers(at time.Time) bool {
+	return !at.Before(t.ValidFrom) && at.Before(t.ValidTo)
+}
+
+// TAFForecast is the forecast a TAF made for a time
+type TAFForecast struct {
+	Station string    `json:"station"`
+	Issued  time.Time `json:"issued"`
+	Time    time.Time `json:"time"`
+
+	// Prevailing conditions after applying FM and completed BECMG groups
+	Prevailing *TAFConditions `json:"prevailing"`
+
+	// TEMPO, PROB and in progress BECMG groups covering the time
+	Temporary []*TAFGroup `json:"temporary"`
+}
+
+// ForecastAt resolves the conditions forecast for a time. False when the
+// time is outside of the TAF's valid period
+func (t *TAF) ForecastAt(at time.Time) (*TAFForecast, bool) {
+	if !t.Covers(at) {
+		return nil, false
+	}
+
+	forecast := &TAFForecast{
+		Station:    t.Station,
+		Issued:     t.Issued,
+		Time:       at,
+		Prevailing: &TAFConditions{},
+		Temporary:  []*TAFGroup{},
+	}
+
+	for _, group := range t.Groups {
+		switch group.Type {
+		case TAFBase:
+			forecast.Prevailing.apply(&group.TAFConditions)
+
+		case TAFFrom:
+			if !group.Start.After(at) {
+				// FM groups replace all previous conditions
+				conditions := group.TAFConditions
+				forecast.Prevailing = &conditions
+			}
+
+		case TAFBecoming:
+			if !group.End.After(at) {
+				forecast.Prevailing.apply(&group.TAFConditions)
+			} else if group.Covers(at) {
+				forecast.Temporary = append(forecast.Temporary, group)
+			}
+
+		case TAFTempo, TAFProb:
+			if group.Covers(at) {
+				forecast.Temporary = append(forecast.Temporary, group)
+			}
+		}
+	}
+
+	return forecast, true
+}
+
+// Overwrites conditions with the ones forecast by changes
+func (c *TAFConditions) apply(changes *TAFConditions) {
+	if changes.Wind != nil {
+		c.Wind = changes.Wind
+	}
+
+	if changes.Visibility != nil {
+		c.Visibility = changes.Visibility
+	}
+
+	if changes.Weather != nil {
+		c.Weather = changes.Weather
+	}
+
+	if changes.Clouds != nil {
+		c.Clouds = changes.Clouds
+	}
+
+	if changes.WindShear != "" {
+		c.WindShear = changes.WindShear
+	}
+}
+
+// CeilingFt is the base of the lowest broken, overcast or obscured layer [ft].
+// False when there is no ceiling
+func (c *TAFConditions) CeilingFt() (float64, bool) {
+	for _, cloud := range c.Clouds {
+		if cloud.Coverage == "BKN" || cloud.Coverage == "OVC" || cloud.Coverage == "VV" {
+			return cloud.HeightFt, true
+		}
+	}
+
+	return 0, false
+}
+
+// FlightCategory of the forecast conditions
+func (c *TAFConditions) FlightCategory() FlightCategory {
+	ceiling, hasCeiling := c.CeilingFt()
+	visibility := -1.0
+
+	if c.Visibility != nil {
+		visibility = c.Visibility.Miles
+	}
+
+	return flightCategory(ceiling, hasCeiling, visibility)
+}
+
+// FlightCategory of an observation. The observation needs the visibility and
+// cloud coverage/height columns to be requested
+func (d *IEMWeatherData) FlightCategory() FlightCategory {
+	layers := []struct {
+		coverage string
+		height   float64
+	}{
+		{d.CloudCoverageL1, d.CloudHeightL1},
+		{d.CloudCoverageL2, d.CloudHeightL2},
+		{d.CloudCoverageL3, d.CloudHeightL3},
+	}
+
+	ceiling, hasCeiling := 0.0, false
+
+	for _, layer := range layers {
+		if layer.coverage == "BKN" || layer.coverage == "OVC" || layer.coverage == "VV" {
+			ceiling, hasCeiling = layer.height, true
+			break
+		}
+	}
+
+	visibility := d.Visibility
+
+	if visibility == 0 {
+		visibility = -1
+	}
+
+	return flightCategory(ceiling, hasCeiling, visibility)
+}
+
+// A negative visibility is treated as unknown
+func flightCategory(ceiling float64, hasCeiling bool, visibility float64) FlightCategory {
+	switch {
+	case (hasCeiling && ceiling < 500) || (visibility >= 0 && visibility < 1):
+		return LIFR
+	case (hasCeiling && ceiling < 1000) || (visibility >= 0 && visibility < 3):
+		return IFR
+	case (hasCeiling && ceiling <= 3000) || (visibility >= 0 && visibility <= 5):
+		return MVFR
+	}
+
+	return VFR
+}
+
+type TAFParseError struct {
+	msg string
+}
+
+func (err TAFParseError) Error() string {
+	return err.msg
+}
+
+func tafParseError(format string, a ...any) TAFParseError {
+	return TAFParseError{msg: fmt.Sprintf("TAF: "+format, a...)}
+}
+
+var (
+	tafIssuedPattern   = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	tafPeriodPattern   = regexp.MustCompile(`^(\d{2})(\d{2})/(\d{2})(\d{2})$`)
+	tafFromPattern     = regexp.MustCompile(`^FM(\d{2})(\d{2})(\d{2})$`)
+	tafProbPattern     = regexp.MustCompile(`^PROB(\d{2})$`)
+	tafWindPattern     = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS)$`)
+	tafVisPattern      = regexp.MustCompile(`^([PM])?(\d+)?(?:(\d)/(\d{1,2}))?SM$`)
+	tafCloudPattern    = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3})(CB|TCU)?$`)
+	tafWeatherPattern  = regexp.MustCompile(`^(\+|-|VC)?(MI|PR|BC|DR|BL|SH|TS|FZ)?(DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*$`)
+	tafWholePattern    = regexp.MustCompile(`^\d$`)
+	tafFractionPattern = regexp.MustCompile(`^\d/\dSM$`)
+)
+
+const knotsPerMPS = 1.943844
+
+// ParseTAF decodes a raw TAF. The reference time resolves the month and year
+// of the day/hour times in the TAF and should be close to the issuance time
+func ParseTAF(raw string, reference time.Time) (*TAF, error) {
+	tokens := strings.Fields(strings.ReplaceAll(raw, "=", " "))
+
+	taf := &TAF{
+		Raw:    strings.Join(tokens, " "),
+		Groups: []*TAFGroup{},
+	}
+
+	i := 0
+
+	next := func() (string, bool) {
+		if i >= len(tokens) {
+			return "", false
+		}
+
+		i++
+		return tokens[i-1], true
+	}
+
+	if len(tokens) > 0 && tokens[0] == "TAF" {
+		i++
+	}
+
+	for i < len(tokens) && (tokens[i] == "AMD" || tokens[i] == "COR") {
+		taf.Amendment = taf.Amendment || tokens[i] == "AMD"
+		taf.Correction = taf.Correction || tokens[i] == "COR"
+		i++
+	}
+
+	station, ok := next()
+
+	if !ok {
+		return nil, tafParseError("missing station")
+	}
+
+	taf.Station = station
+
+	issued, _ := next()
+	match := tafIssuedPattern.FindStringSubmatch(issued)
+
+	if match == nil {
+		return nil, tafParseError("invalid issuance time %q", issued)
+	}
+
+	taf.Issued = tafTime(reference, atoi(match[1]), atoi(match[2]), atoi(match[3]))
+
+	period, _ := next()
+	start, end, ok := tafPeriod(period, taf.Issued)
+
+	if !ok {
+		return nil, tafParseError("invalid valid period %q", period)
+	}
+
+	taf.ValidFrom, taf.ValidTo = start, end
+
+	group := &TAFGroup{Type: TAFBase, Start: start, End: end}
+	groupStart := i
+
+	finish := func() {
+		group.Raw = strings.Join(tokens[groupStart:i], " ")
+		taf.Groups = append(taf.Groups, group)
+	}
+
+	for i < len(tokens) {
+		token := tokens[i]
+
+		if token == "RMK" {
+			break
+		}
+
+		if match := tafFromPattern.FindStringSubmatch(token); match != nil {
+			finish()
+			groupStart = i
+			i++
+
+			group = &TAFGroup{
+				Type:  TAFFrom,
+				Start: tafTime(taf.Issued, atoi(match[1]), atoi(match[2]), atoi(match[3])),
+				End:   taf.ValidTo,
+			}
+
+			continue
+		}
+
+		if token == "TEMPO" || token == "BECMG" || tafProbPattern.MatchString(token) {
+			finish()
+			groupStart = i
+			i++
+
+			group = &TAFGroup{Type: TAFChangeType(token)}
+
+			if match := tafProbPattern.FindStringSubmatch(token); match != nil {
+				group.Type = TAFProb
+				group.Probability = atoi(match[1])
+
+				// PROB30 TEMPO 0502/0506
+				if i < len(tokens) && tokens[i] == "TEMPO" {
+					i++
+				}
+			}
+
+			period, _ := next()
+			start, end, ok := tafPeriod(period, taf.Issued)
+
+			if !ok {
+				return nil, tafParseError("invalid %s period %q", group.Type, period)
+			}
+
+			group.Start, group.End = start, end
+
+			continue
+		}
+
+		i++
+
+		if err := group.decode(token, tokens, &i); err != nil {
+			return nil, err
+		}
+	}
+
+	finish()
+
+	// FM groups end when the next FM group starts
+	var previous *TAFGroup
+
+	for _, group := range taf.Groups {
+		if group.Type != TAFBase && group.Type != TAFFrom {
+			continue
+		}
+
+		if previous != nil {
+			previous.End = group.Start
+		}
+
+		previous = group
+	}
+
+	return taf, nil
+}
+
+// Decodes a condition token into the group. i points past the token and is
+// advanced when the token spans multiple fields (1 1/2SM)
+func (g *TAFGroup) decode(token string, tokens []string, i *int) error {
+	if match := tafWindPattern.FindStringSubmatch(token); match != nil {
+		wind := &TAFWind{
+			SpeedKnots: float64(atoi(match[2])),
+			GustKnots:  float64(atoi(match[3])),
+		}
+
+		if match[1] == "VRB" {
+			wind.Variable = true
+		} else {
+			wind.Direction = float64(atoi(match[1]))
+		}
+
+		if match[4] == "MPS" {
+			wind.SpeedKnots *= knotsPerMPS
+			wind.GustKnots *= knotsPerMPS
+		}
+
+		g.Wind = wind
+		return nil
+	}
+
+	// Whole number followed by a fraction (1 1/2SM)
+	if tafWholePattern.MatchString(token) && *i < len(tokens) && tafFractionPattern.MatchString(tokens[*i]) {
+		token = token + " " + tokens[*i]
+		*i++
+	}
+
+	if visibility, ok := tafVisibility(token); ok {
+		g.Visibility = visibility
+		return nil
+	}
+
+	if token == "SKC" || token == "NSC" || token == "CLR" {
+		g.Clouds = []*TAFCloud{}
+		return nil
+	}
+
+	if match := tafCloudPattern.FindStringSubmatch(token); match != nil {
+		g.Clouds = append(g.Clouds, &TAFCloud{
+			Coverage: match[1],
+			HeightFt: float64(atoi(match[2]) * 100),
+			Type:     match[3],
+		})
+
+		return nil
+	}
+
+	if token == "NSW" {
+		g.Weather = []string{}
+		return nil
+	}
+
+	if strings.HasPrefix(token, "WS") {
+		g.WindShear = token
+		return nil
+	}
+
+	if token != "" && tafWeatherPattern.MatchString(token) {
+		g.Weather = append(g.Weather, token)
+		return nil
+	}
+
+	// Unknown groups (QNH, AUTOMATED, etc) are kept in the raw text only
+	return nil
+}
+
+func tafVisibility(token string) (*TAFVisibility, bool) {
+	whole := ""
+
+	if parts := strings.Fields(token); len(parts) == 2 {
+		whole, token = parts[0], parts[1]
+	}
+
+	match := tafVisPattern.FindStringSubmatch(token)
+
+	if match == nil || (match[2] == "" && match[3] == "") {
+		return nil, false
+	}
+
+	miles := float64(atoi(match[2]) + atoi(whole))
+
+	if match[3] != "" {
+		miles += float64(atoi(match[3])) / float64(atoi(match[4]))
+	}
+
+	return &TAFVisibility{Miles: miles, GreaterThan: match[1] == "P"}, true
+}
+
+func tafPeriod(token string, issued time.Time) (time.Time, time.Time, bool) {
+	match := tafPeriodPattern.FindStringSubmatch(token)
+
+	if match == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start := tafTime(issued, atoi(match[1]), atoi(match[2]), 0)
+	end := tafTime(start, atoi(match[3]), atoi(match[4]), 0)
+
+	return start, end, true
+}
+
+// Resolves a day of month and time to the date closest to reference. Hour 24
+// is midnight at the end of the day
+func tafTime(reference time.Time, day, hour, minute int) time.Time {
+	reference = reference.UTC()
+
+	var best time.Time
+
+	for _, monthOffset := range []int{-1, 0, 1} {
+		candidate := time.Date(reference.Year(), reference.Month()+time.Month(monthOffset), day, hour, minute, 0, 0, time.UTC)
+
+		// time.Date normalizes days past the end of the month into the next month
+		if candidate.Add(-time.Duration(hour)*time.Hour).Day() != day {
+			continue
+		}
+
+		if best.IsZero() || absDuration(candidate.Sub(reference)) < absDuration(best.Sub(reference)) {
+			best = candidate
+		}
+	}
+
+	return best
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
+type TAFService interface {
+	// Gets the TAFs for a station issued between start and end
+	GetTAFs(ctx context.Context, stationId string, start time.Time, end time.Time) ([]*TAF, error)
+
+	// Gets what the latest TAF issued before t forecast for t
+	ForecastAt(ctx context.Context, stationId string, t time.Time) (*TAFForecast, error)
+}
+
+type IEMTAFService struct {
+	client *Client
+}
+
+// TAFs are issued at least every 6 hours and are valid for up to 30 hours
+const tafLookback = 30 * time.Hour
+
+func (s *IEMTAFService) GetTAFs(ctx context.Context, stationId string, start time.Time, end time.Time) ([]*TAF, error) {
+	v := url.Values{}
+	v.Add("station", stationId)
+	v.Add("sts", start.UTC().Format("2006-01-02T15:04Z"))
+	v.Add("ets", end.UTC().Format("2006-01-02T15:04Z"))
+	v.Add("fmt", "csv")
+
+	url := fmt.Sprintf("/cgi-bin/request/taf.py?%s", v.Encode())
+
+	var tafs []*TAF
+
+	err := s.client.get(ctx, url, func(body io.Reader) (err error) {
+		tafs, err = parseTAFArchive(body)
+		return err
+	})
+
+	return tafs, err
+}
+
+func (s *IEMTAFService) ForecastAt(ctx context.Context, stationId string, t time.Time) (*TAFForecast, error) {
+	tafs, err := s.GetTAFs(ctx, stationId, t.Add(-tafLookback), t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *TAF
+
+	for _, taf := range tafs {
+		if taf.Issued.After(t) || !taf.Covers(t) {
+			continue
+		}
+
+		if latest == nil || taf.Issued.After(latest.Issued) {
+			latest = taf
+		}
+	}
+
+	if latest == nil {
+		return nil, IEMNotFoundError{
+			Detail: fmt.Sprintf("no TAF for %s covers %s", stationId, t.UTC().Format(time.RFC3339)),
+			Code:   404,
+		}
+	}
+
+	forecast, _ := latest.ForecastAt(t)
+
+	return forecast, nil
+}
+
+// Parses IEM's TAF archive CSV. Rows are grouped by station and issuance
+// time and their raw text is decoded
+func parseTAFArchive(reader io.Reader) ([]*TAF, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+
+	if err == io.EOF {
+		return []*TAF{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	indecies := map[string]int{}
+
+	for i, column := range header {
+		indecies[column] = i
+	}
+
+	// Rows must have every column up to the last one that is read
+	columns := 0
+
+	for _, column := range []string{"station", "valid", "raw"} {
+		i, ok := indecies[column]
+
+		if !ok {
+			return nil, tafParseError("archive is missing the %s column", column)
+		}
+
+		columns = max(columns, i+1)
+	}
+
+	type issuance struct {
+		station string
+		issued  time.Time
+		raw     []string
+	}
+
+	issuances := []*issuance{}
+	byKey := map[string]*issuance{}
+
+	for row := 1; ; row++ {
+		record, err := csvReader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) < columns {
+			return nil, tafParseError("archive row %d has %d columns, expected %d", row, len(record), columns)
+		}
+
+		valid := record[indecies["valid"]]
+		issued, err := parseIEMTime(valid)
+
+		if err != nil {
+			return nil, fmt.Errorf("error parsing valid [%w]", err)
+		}
+
+		key := record[indecies["station"]] + valid
+		current, ok := byKey[key]
+
+		if !ok {
+			current = &issuance{station: record[indecies["station"]], issued: issued}
+			byKey[key] = current
+			issuances = append(issuances, current)
+		}
+
+		raw := strings.TrimSpace(record[indecies["raw"]])
+
+		if raw != "" && (len(current.raw) == 0 || current.raw[len(current.raw)-1] != raw) {
+			current.raw = append(current.raw, raw)
+		}
+	}
+
+	tafs := []*TAF{}
+
+	for _, issuance := range issuances {
+		taf, err := ParseTAF(strings.Join(issuance.raw, " "), issuance.issued)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tafs = append(tafs, taf)
+	}
+
+	return tafs, nil
+}
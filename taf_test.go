@@ -0,0 +1,118 @@
+package iem
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTAF = `TAF KDSM 041120Z 0412/0512 18012G20KT P6SM SCT250
+  FM041800 20015G25KT 5SM -SHRA BKN050
+  TEMPO 0420/0424 1 1/2SM TSRA BKN020CB
+  FM050200 29010KT P6SM SKC
+  BECMG 0506/0508 VRB03KT=`
+
+func TestParseTAF(t *testing.T) {
+	reference := time.Date(2023, 10, 4, 11, 20, 0, 0, time.UTC)
+
+	taf, err := ParseTAF(testTAF, reference)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if taf.Station != "KDSM" || !taf.Issued.Equal(reference) {
+		t.Fatalf("unexpected header %s %v", taf.Station, taf.Issued)
+	}
+
+	if !taf.ValidTo.Equal(time.Date(2023, 10, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected valid to %v", taf.ValidTo)
+	}
+
+	if len(taf.Groups) != 5 {
+		t.Fatalf("expected 5 groups, got %d", len(taf.Groups))
+	}
+
+	base := taf.Groups[0]
+
+	if base.Wind.Direction != 180 || base.Wind.SpeedKnots != 12 || base.Wind.GustKnots != 20 {
+		t.Errorf("unexpected base wind %+v", base.Wind)
+	}
+
+	if !base.Visibility.GreaterThan || base.Visibility.Miles != 6 {
+		t.Errorf("unexpected base visibility %+v", base.Visibility)
+	}
+
+	if !base.End.Equal(time.Date(2023, 10, 4, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("base should end at the first FM group, got %v", base.End)
+	}
+
+	tempo := taf.Groups[2]
+
+	if tempo.Type != TAFTempo || tempo.Visibility.Miles != 1.5 || tempo.Clouds[0].Type != "CB" {
+		t.Errorf("unexpected tempo group %+v", tempo)
+	}
+
+	if !tempo.End.Equal(time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("hour 24 should be midnight, got %v", tempo.End)
+	}
+}
+
+func TestTAFForecastAt(t *testing.T) {
+	taf, err := ParseTAF(testTAF, time.Date(2023, 10, 4, 11, 20, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forecast, ok := taf.ForecastAt(time.Date(2023, 10, 4, 21, 0, 0, 0, time.UTC))
+
+	if !ok {
+		t.Fatal("expected forecast")
+	}
+
+	if forecast.Prevailing.Wind.Direction != 200 || strings.Join(forecast.Prevailing.Weather, " ") != "-SHRA" {
+		t.Errorf("unexpected prevailing %+v", forecast.Prevailing)
+	}
+
+	if len(forecast.Temporary) != 1 || forecast.Temporary[0].Type != TAFTempo {
+		t.Errorf("expected tempo group, got %+v", forecast.Temporary)
+	}
+
+	if category := forecast.Prevailing.FlightCategory(); category != MVFR {
+		t.Errorf("expected MVFR, got %s", category)
+	}
+
+	forecast, _ = taf.ForecastAt(time.Date(2023, 10, 5, 9, 0, 0, 0, time.UTC))
+
+	if !forecast.Prevailing.Wind.Variable || forecast.Prevailing.Visibility.Miles != 6 || len(forecast.Prevailing.Clouds) != 0 {
+		t.Errorf("expected becoming group applied, got %+v", forecast.Prevailing)
+	}
+
+	if _, ok := taf.ForecastAt(time.Date(2023, 10, 5, 12, 0, 0, 0, time.UTC)); ok {
+		t.Error("expected no forecast after valid period")
+	}
+}
+
+func TestParseTAFArchiveShortRow(t *testing.T) {
+	archive := "station,valid,raw\nKDSM,2023-10-04 11:20,TAF KDSM 041120Z 0412/0512 18012KT P6SM SKC\nKDSM,2023-10-04 17:20\n"
+
+	_, err := parseTAFArchive(strings.NewReader(archive))
+
+	var parseErr TAFParseError
+
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a TAFParseError, got %v", err)
+	}
+
+	tafs, err := parseTAFArchive(strings.NewReader("station,valid,raw\nKDSM,2023-10-04T11:20:00Z,TAF KDSM 041120Z 0412/0512 18012KT P6SM SKC\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tafs) != 1 || !tafs[0].Issued.Equal(time.Date(2023, 10, 4, 11, 20, 0, 0, time.UTC)) {
+		t.Errorf("unexpected TAFs %+v", tafs)
+	}
+}
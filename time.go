@@ -0,0 +1,27 @@
+package iem
+
+import "time"
+
+var iemTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Parses the time formats used across IEM's JSON services
+func parseIEMTime(value string) (time.Time, error) {
+	var err error
+
+	for _, layout := range iemTimeLayouts {
+		var t time.Time
+
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
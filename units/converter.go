@@ -0,0 +1,259 @@
+package units
+
+import (
+	"time"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+type System int
+
+const (
+	SI System = iota
+	USCustomary
+)
+
+// Units of each dimension in a system of units
+type systemUnits struct {
+	temperature TemperatureUnit
+	speed       SpeedUnit
+	precip      LengthUnit
+	distance    LengthUnit
+	height      LengthUnit
+	pressure    PressureUnit
+}
+
+var systems = map[System]systemUnits{
+	SI:          {Celsius, MetersPerSecond, Millimeter, Kilometer, Meter, Hectopascal},
+	USCustomary: {Fahrenheit, MilesPerHour, Inch, Mile, Foot, InchesOfMercury},
+}
+
+// Observation is an IEMWeatherData observation with its values in a system of
+// units. Properties are nil when the value was missing and 0 when it was a trace
+type Observation struct {
+	Station string     `json:"station"`
+	Time    *time.Time `json:"time"`
+
+	Temperature *Temperature `json:"temperature,omitempty"`
+	DewPoint    *Temperature `json:"dew_point,omitempty"`
+	Feel        *Temperature `json:"feel,omitempty"`
+
+	RelativeHumidity *float64 `json:"relh,omitempty"` // [%]
+	WindDirection    *float64 `json:"drct,omitempty"` // [deg]
+
+	WindSpeed    *Speed `json:"wind_speed,omitempty"`
+	WindGust     *Speed `json:"wind_gust,omitempty"`
+	PeakWindGust *Speed `json:"peak_wind_gust,omitempty"`
+
+	Altimeter        *Pressure `json:"altimeter,omitempty"`
+	SeaLevelPressure *Pressure `json:"sea_level_pressure,omitempty"`
+
+	Precip         *Length `json:"precip,omitempty"`
+	PrecipTrace    bool    `json:"precip_trace,omitempty"` // Precip is a trace amount of 0
+	Visibility     *Length `json:"visibility,omitempty"`
+	SnowDepth      *Length `json:"snow_depth,omitempty"`
+	SnowDepthTrace bool    `json:"snow_depth_trace,omitempty"` // SnowDepth is a trace amount of 0
+
+	CloudHeightL1 *Length `json:"skyl1,omitempty"`
+	CloudHeightL2 *Length `json:"skyl2,omitempty"`
+	CloudHeightL3 *Length `json:"skyl3,omitempty"`
+}
+
+// Converter converts observations into a system of units
+type Converter struct {
+	System System
+}
+
+func NewConverter(system System) *Converter {
+	return &Converter{System: system}
+}
+
+// Values of missing columns are nil and traces are 0. Conversions between the
+// known units of a system can not fail
+func (c *Converter) temperature(d *iem.IEMWeatherData, column iem.WeatherDataData, unit TemperatureUnit) *Temperature {
+	value, ok := c.value(d, column)
+
+	if !ok {
+		return nil
+	}
+
+	t, _ := Temperature{value, unit}.In(systems[c.System].temperature)
+	return &t
+}
+
+func (c *Converter) speed(d *iem.IEMWeatherData, column iem.WeatherDataData, unit SpeedUnit) *Speed {
+	value, ok := c.value(d, column)
+
+	if !ok {
+		return nil
+	}
+
+	s, _ := Speed{value, unit}.In(systems[c.System].speed)
+	return &s
+}
+
+func (c *Converter) length(d *iem.IEMWeatherData, column iem.WeatherDataData, unit LengthUnit, to LengthUnit) *Length {
+	value, ok := c.value(d, column)
+
+	if !ok {
+		return nil
+	}
+
+	l, _ := Length{value, unit}.In(to)
+	return &l
+}
+
+func (c *Converter) pressure(d *iem.IEMWeatherData, column iem.WeatherDataData, unit PressureUnit) *Pressure {
+	value, ok := c.value(d, column)
+
+	if !ok {
+		return nil
+	}
+
+	p, _ := Pressure{value, unit}.In(systems[c.System].pressure)
+	return &p
+}
+
+func (c *Converter) value(d *iem.IEMWeatherData, column iem.WeatherDataData) (float64, bool) {
+	if d.IsMissing(column) {
+		return 0, false
+	}
+
+	return d.Float(column)
+}
+
+func (c *Converter) float(d *iem.IEMWeatherData, column iem.WeatherDataData) *float64 {
+	value, ok := c.value(d, column)
+
+	if !ok {
+		return nil
+	}
+
+	return &value
+}
+
+// Convert converts an observation into the converter's system of units. Fields
+// are read from either unit of paired fields (tmpf/tmpc, sknt/sped, p01i/p01m, etc)
+func (c *Converter) Convert(d *iem.IEMWeatherData) *Observation {
+	units := systems[c.System]
+	filled := *d
+	FillPairs(&filled)
+
+	return &Observation{
+		Station: d.Station,
+		Time:    d.Time,
+
+		Temperature: c.temperature(&filled, iem.TempF, Fahrenheit),
+		DewPoint:    c.temperature(&filled, iem.DewPointF, Fahrenheit),
+		Feel:        c.temperature(&filled, iem.Feel, Fahrenheit),
+
+		RelativeHumidity: c.float(d, iem.RelativeHumidity),
+		WindDirection:    c.float(d, iem.WindDirection),
+
+		WindSpeed:    c.speed(&filled, iem.WindSpeedKnots, Knots),
+		WindGust:     c.speed(&filled, iem.WindGustKnots, Knots),
+		PeakWindGust: c.speed(&filled, iem.PeakWindGustKnots, Knots),
+
+		Altimeter:        c.pressure(d, iem.Altimeter, InchesOfMercury),
+		SeaLevelPressure: c.pressure(d, iem.SeaLevelPressure, Millibar),
+
+		Precip:         c.length(&filled, iem.PrecipInch, Inch, units.precip),
+		PrecipTrace:    filled.IsTrace(iem.PrecipInch),
+		Visibility:     c.length(d, iem.Visibility, Mile, units.distance),
+		SnowDepth:      c.length(d, iem.SnowDepth, Inch, units.precip),
+		SnowDepthTrace: d.IsTrace(iem.SnowDepth),
+
+		CloudHeightL1: c.length(d, iem.CloudHeightL1, Foot, units.height),
+		CloudHeightL2: c.length(d, iem.CloudHeightL2, Foot, units.height),
+		CloudHeightL3: c.length(d, iem.CloudHeightL3, Foot, units.height),
+	}
+}
+
+// Normalize converts every observation into the converter's system of units
+func (c *Converter) Normalize(data []*iem.IEMWeatherData) []*Observation {
+	observations := make([]*Observation, len(data))
+
+	for i, d := range data {
+		observations[i] = c.Convert(d)
+	}
+
+	return observations
+}
+
+// fillPair sets whichever of a pair of columns is missing from the other. A
+// trace in one column is a trace in the other
+func fillPair(d *iem.IEMWeatherData, a iem.WeatherDataData, b iem.WeatherDataData, aToB func(float64) float64, bToA func(float64) float64) {
+	switch {
+	case fillable(d, a, b):
+		fillColumn(d, a, b, aToB)
+	case fillable(d, b, a):
+		fillColumn(d, b, a, bToA)
+	}
+}
+
+// Reports whether a column can be filled from another. It can when it is
+// missing, or is 0 while the other is not, as when an observation created by
+// hand only sets one of the pair
+func fillable(d *iem.IEMWeatherData, from iem.WeatherDataData, to iem.WeatherDataData) bool {
+	if d.IsMissing(from) {
+		return false
+	}
+
+	if d.IsMissing(to) {
+		return true
+	}
+
+	fromValue, _ := d.Float(from)
+	toValue, _ := d.Float(to)
+
+	return toValue == 0 && fromValue != 0 && !d.IsTrace(to)
+}
+
+func fillColumn(d *iem.IEMWeatherData, from iem.WeatherDataData, to iem.WeatherDataData, convert func(float64) float64) {
+	if d.IsTrace(from) {
+		d.SetTrace(to)
+		return
+	}
+
+	value, _ := d.Float(from)
+	d.SetFloat(to, convert(value))
+}
+
+func speedPair(from SpeedUnit, to SpeedUnit) func(float64) float64 {
+	return func(v float64) float64 {
+		s, _ := Speed{v, from}.In(to)
+		return s.Value
+	}
+}
+
+func temperaturePair(from TemperatureUnit, to TemperatureUnit) func(float64) float64 {
+	return func(v float64) float64 {
+		t, _ := Temperature{v, from}.In(to)
+		return t.Value
+	}
+}
+
+func lengthPair(from LengthUnit, to LengthUnit) func(float64) float64 {
+	return func(v float64) float64 {
+		l, _ := Length{v, from}.In(to)
+		return l.Value
+	}
+}
+
+// FillPairs sets the missing field of each pair of fields IEM returns in two
+// units from the other (TemperatureC from TemperatureF, PrecipMM from
+// PrecipInch, WindSpeedMPH from WindSpeedKnots, etc)
+func FillPairs(data ...*iem.IEMWeatherData) {
+	fToC, cToF := temperaturePair(Fahrenheit, Celsius), temperaturePair(Celsius, Fahrenheit)
+	ktToMPH, mphToKt := speedPair(Knots, MilesPerHour), speedPair(MilesPerHour, Knots)
+	inToMM, mmToIn := lengthPair(Inch, Millimeter), lengthPair(Millimeter, Inch)
+
+	for _, d := range data {
+		fillPair(d, iem.TempF, iem.TempC, fToC, cToF)
+		fillPair(d, iem.DewPointF, iem.DewPointC, fToC, cToF)
+		fillPair(d, iem.WindSpeedKnots, iem.WindSpeedMPH, ktToMPH, mphToKt)
+		fillPair(d, iem.WindGustKnots, iem.WindGustMPH, ktToMPH, mphToKt)
+		fillPair(d, iem.PeakWindGustKnots, iem.PeakWindGustMPH, ktToMPH, mphToKt)
+		fillPair(d, iem.PrecipInch, iem.PrecipMM, inToMM, mmToIn)
+	}
+}
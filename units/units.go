@@ -0,0 +1,185 @@
+// Package units converts IEMWeatherData values between SI and US customary
+// units with quantity types that carry their unit
+package units
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type TemperatureUnit string
+
+const (
+	Fahrenheit TemperatureUnit = "F"
+	Celsius    TemperatureUnit = "C"
+	Kelvin     TemperatureUnit = "K"
+)
+
+type SpeedUnit string
+
+const (
+	Knots             SpeedUnit = "kt"
+	MilesPerHour      SpeedUnit = "mph"
+	MetersPerSecond   SpeedUnit = "m/s"
+	KilometersPerHour SpeedUnit = "km/h"
+)
+
+type LengthUnit string
+
+const (
+	Inch       LengthUnit = "in"
+	Foot       LengthUnit = "ft"
+	Mile       LengthUnit = "mi"
+	Millimeter LengthUnit = "mm"
+	Meter      LengthUnit = "m"
+	Kilometer  LengthUnit = "km"
+)
+
+type PressureUnit string
+
+const (
+	InchesOfMercury PressureUnit = "inHg"
+	Millibar        PressureUnit = "mb"
+	Hectopascal     PressureUnit = "hPa"
+	Kilopascal      PressureUnit = "kPa"
+)
+
+// Size of each unit in the base unit of its dimension (m/s, m and mb)
+var speedFactors = map[SpeedUnit]float64{
+	Knots:             0.514444,
+	MilesPerHour:      0.44704,
+	MetersPerSecond:   1,
+	KilometersPerHour: 1 / 3.6,
+}
+
+var lengthFactors = map[LengthUnit]float64{
+	Inch:       0.0254,
+	Foot:       0.3048,
+	Mile:       1609.344,
+	Millimeter: 0.001,
+	Meter:      1,
+	Kilometer:  1000,
+}
+
+var pressureFactors = map[PressureUnit]float64{
+	InchesOfMercury: 33.8639,
+	Millibar:        1,
+	Hectopascal:     1,
+	Kilopascal:      10,
+}
+
+// UnitError is returned when converting to or from an unknown unit
+type UnitError struct {
+	Unit string
+}
+
+func (err UnitError) Error() string {
+	return fmt.Sprintf("unknown unit %s", err.Unit)
+}
+
+func convert[U ~string](value float64, from U, to U, factors map[U]float64) (float64, error) {
+	fromFactor, ok := factors[from]
+
+	if !ok {
+		return 0, UnitError{string(from)}
+	}
+
+	toFactor, ok := factors[to]
+
+	if !ok {
+		return 0, UnitError{string(to)}
+	}
+
+	return value * fromFactor / toFactor, nil
+}
+
+func format(value float64, unit string) string {
+	return strconv.FormatFloat(value, 'f', 2, 64) + " " + unit
+}
+
+type Temperature struct {
+	Value float64         `json:"value"`
+	Unit  TemperatureUnit `json:"unit"`
+}
+
+func (t Temperature) kelvin() (float64, error) {
+	switch t.Unit {
+	case Fahrenheit:
+		return (t.Value-32)*5/9 + 273.15, nil
+	case Celsius:
+		return t.Value + 273.15, nil
+	case Kelvin:
+		return t.Value, nil
+	}
+
+	return 0, UnitError{string(t.Unit)}
+}
+
+// In converts the temperature to another unit
+func (t Temperature) In(unit TemperatureUnit) (Temperature, error) {
+	k, err := t.kelvin()
+
+	if err != nil {
+		return Temperature{}, err
+	}
+
+	switch unit {
+	case Fahrenheit:
+		return Temperature{(k-273.15)*9/5 + 32, unit}, nil
+	case Celsius:
+		return Temperature{k - 273.15, unit}, nil
+	case Kelvin:
+		return Temperature{k, unit}, nil
+	}
+
+	return Temperature{}, UnitError{string(unit)}
+}
+
+func (t Temperature) String() string {
+	return format(t.Value, string(t.Unit))
+}
+
+type Speed struct {
+	Value float64   `json:"value"`
+	Unit  SpeedUnit `json:"unit"`
+}
+
+// In converts the speed to another unit
+func (s Speed) In(unit SpeedUnit) (Speed, error) {
+	v, err := convert(s.Value, s.Unit, unit, speedFactors)
+	return Speed{v, unit}, err
+}
+
+func (s Speed) String() string {
+	return format(s.Value, string(s.Unit))
+}
+
+type Length struct {
+	Value float64    `json:"value"`
+	Unit  LengthUnit `json:"unit"`
+}
+
+// In converts the length to another unit
+func (l Length) In(unit LengthUnit) (Length, error) {
+	v, err := convert(l.Value, l.Unit, unit, lengthFactors)
+	return Length{v, unit}, err
+}
+
+func (l Length) String() string {
+	return format(l.Value, string(l.Unit))
+}
+
+type Pressure struct {
+	Value float64      `json:"value"`
+	Unit  PressureUnit `json:"unit"`
+}
+
+// In converts the pressure to another unit
+func (p Pressure) In(unit PressureUnit) (Pressure, error) {
+	v, err := convert(p.Value, p.Unit, unit, pressureFactors)
+	return Pressure{v, unit}, err
+}
+
+func (p Pressure) String() string {
+	return format(p.Value, string(p.Unit))
+}
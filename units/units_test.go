@@ -0,0 +1,90 @@
+package units
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	iem "github.com/colevoss/go-iem-sdk"
+)
+
+func assertClose(t *testing.T, name string, got, expected float64) {
+	t.Helper()
+
+	if math.Abs(got-expected) > 0.01 {
+		t.Errorf("%s: expected %f got %f", name, expected, got)
+	}
+}
+
+func TestQuantities(t *testing.T) {
+	c, err := Temperature{212, Fahrenheit}.In(Celsius)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "temperature", c.Value, 100)
+
+	s, _ := Speed{10, Knots}.In(MetersPerSecond)
+	assertClose(t, "speed", s.Value, 5.144)
+
+	l, _ := Length{1, Inch}.In(Millimeter)
+	assertClose(t, "length", l.Value, 25.4)
+
+	p, _ := Pressure{29.92, InchesOfMercury}.In(Millibar)
+	assertClose(t, "pressure", p.Value, 1013.21)
+
+	if _, err := (Speed{1, "furlong/fortnight"}).In(Knots); err == nil {
+		t.Error("expected unknown unit error")
+	}
+}
+
+func TestFillPairsAndNormalize(t *testing.T) {
+	d := &iem.IEMWeatherData{TemperatureF: 50, WindSpeedKnots: 10, PrecipMM: 25.4, Visibility: 10}
+
+	FillPairs(d)
+
+	assertClose(t, "tmpc", d.TemperatureC, 10)
+	assertClose(t, "sped", d.WindSpeedMPH, 11.51)
+	assertClose(t, "p01i", d.PrecipInch, 1)
+
+	observation := NewConverter(SI).Convert(d)
+
+	if observation.Temperature.Unit != Celsius || observation.DewPoint != nil {
+		t.Errorf("unexpected temperatures %v %v", observation.Temperature, observation.DewPoint)
+	}
+
+	assertClose(t, "visibility", observation.Visibility.Value, 16.09)
+}
+
+func TestNormalizeObservedZeros(t *testing.T) {
+	body := "station,valid,tmpc,sknt,p01i,snowdepth\nLNK,2023-01-04 06:54,0.00,0.00,T,M\n"
+	data, err := iem.ParseWeatherData(strings.NewReader(body), iem.NewWeatherDataQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observation := NewConverter(USCustomary).Convert(data[0])
+
+	if observation.Temperature == nil || observation.WindSpeed == nil || observation.Precip == nil {
+		t.Fatalf("expected observed zeros to convert, got %+v", observation)
+	}
+
+	assertClose(t, "temperature", observation.Temperature.Value, 32)
+	assertClose(t, "wind speed", observation.WindSpeed.Value, 0)
+
+	if !observation.PrecipTrace || observation.Precip.Value != 0 {
+		t.Errorf("expected trace precip, got %v %v", observation.Precip, observation.PrecipTrace)
+	}
+
+	if observation.SnowDepth != nil || observation.DewPoint != nil {
+		t.Errorf("expected missing values to be nil, got %v %v", observation.SnowDepth, observation.DewPoint)
+	}
+
+	FillPairs(data[0])
+
+	if data[0].IsMissing(iem.TempF) || !data[0].IsTrace(iem.PrecipMM) || data[0].IsMissing(iem.WindSpeedMPH) {
+		t.Errorf("expected paired columns to be filled, got %+v", data[0])
+	}
+}
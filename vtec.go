@@ -0,0 +1,359 @@
+package iem
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type VTECAction string
+
+const (
+	VTECNew        VTECAction = "NEW" // New event
+	VTECContinue   VTECAction = "CON" // Event continued
+	VTECExtendTime VTECAction = "EXT" // Event extended (time)
+	VTECExtendArea VTECAction = "EXA" // Event extended (area)
+	VTECExtendBoth VTECAction = "EXB" // Event extended (time and area)
+	VTECUpgrade    VTECAction = "UPG" // Event upgraded
+	VTECCancel     VTECAction = "CAN" // Event cancelled
+	VTECExpire     VTECAction = "EXP" // Event expiring
+	VTECCorrection VTECAction = "COR" // Correction
+	VTECRoutine    VTECAction = "ROU" // Routine
+)
+
+// VTEC is a parsed P-VTEC string
+// /O.NEW.KDMX.TO.W.0001.230401T2200Z-230401T2245Z/
+type VTEC struct {
+	ProductClass string     `json:"product_class"` // O (operational), T (test), E (experimental), X (experimental VTEC)
+	Action       VTECAction `json:"action"`
+	Office       string     `json:"office"`       // Four letter office id (KDMX)
+	Phenomena    string     `json:"phenomena"`    // Two letter phenomena code (TO)
+	Significance string     `json:"significance"` // One letter significance code (W)
+	ETN          int        `json:"etn"`          // Event tracking number
+
+	Begin *time.Time `json:"begin,omitempty"` // Nil when the event is already in effect (000000T0000Z)
+	End   *time.Time `json:"end,omitempty"`   // Nil when the event has no defined end (000000T0000Z)
+}
+
+type VTECParseError struct {
+	msg string
+}
+
+func (err VTECParseError) Error() string {
+	return err.msg
+}
+
+var vtecPattern = regexp.MustCompile(`([OTEX])\.([A-Z]{3})\.([A-Z]{4})\.([A-Z]{2})\.([A-Z])\.([0-9]{4})\.([0-9]{6}T[0-9]{4}Z)-([0-9]{6}T[0-9]{4}Z)`)
+
+const vtecTimeLayout = "060102T1504Z"
+const vtecNoTime = "000000T0000Z"
+
+// Parses a single VTEC string. Surrounding slashes are optional
+func ParseVTEC(value string) (*VTEC, error) {
+	trimmed := strings.Trim(strings.TrimSpace(value), "/")
+	match := vtecPattern.FindStringSubmatch(trimmed)
+
+	if match == nil || match[0] != trimmed {
+		return nil, VTECParseError{
+			msg: fmt.Sprintf("VTEC: invalid VTEC string %q", value),
+		}
+	}
+
+	return vtecFromMatch(match)
+}
+
+// Finds and parses all VTEC strings in a product's text
+func ParseVTECs(text string) ([]*VTEC, error) {
+	vtecs := []*VTEC{}
+
+	for _, match := range vtecPattern.FindAllStringSubmatch(text, -1) {
+		vtec, err := vtecFromMatch(match)
+
+		if err != nil {
+			return nil, err
+		}
+
+		vtecs = append(vtecs, vtec)
+	}
+
+	return vtecs, nil
+}
+
+func vtecFromMatch(match []string) (*VTEC, error) {
+	etn, err := strconv.Atoi(match[6])
+
+	if err != nil {
+		return nil, VTECParseError{msg: fmt.Sprintf("VTEC: invalid ETN %q", match[6])}
+	}
+
+	begin, err := parseVTECTime(match[7])
+
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := parseVTECTime(match[8])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &VTEC{
+		ProductClass: match[1],
+		Action:       VTECAction(match[2]),
+		Office:       match[3],
+		Phenomena:    match[4],
+		Significance: match[5],
+		ETN:          etn,
+		Begin:        begin,
+		End:          end,
+	}, nil
+}
+
+func parseVTECTime(value string) (*time.Time, error) {
+	if value == vtecNoTime {
+		return nil, nil
+	}
+
+	t, err := time.Parse(vtecTimeLayout, value)
+
+	if err != nil {
+		return nil, VTECParseError{msg: fmt.Sprintf("VTEC: invalid time %q", value)}
+	}
+
+	return &t, nil
+}
+
+func formatVTECTime(t *time.Time) string {
+	if t == nil {
+		return vtecNoTime
+	}
+
+	return t.UTC().Format(vtecTimeLayout)
+}
+
+// Formats the VTEC back into its /O.NEW.KDMX.TO.W.0001.230401T2200Z-230401T2245Z/ form
+func (v *VTEC) String() string {
+	return fmt.Sprintf(
+		"/%s.%s.%s.%s.%s.%04d.%s-%s/",
+		v.ProductClass,
+		v.Action,
+		v.Office,
+		v.Phenomena,
+		v.Significance,
+		v.ETN,
+		formatVTECTime(v.Begin),
+		formatVTECTime(v.End),
+	)
+}
+
+// Wfo is the three letter WFO id used by IEM (KDMX -> DMX)
+func (v *VTEC) Wfo() string {
+	if len(v.Office) == 4 {
+		return v.Office[1:]
+	}
+
+	return v.Office
+}
+
+// EventId identifies the event this VTEC string belongs to. The year is taken
+// from the begin time, or the end time when the event is already in effect
+func (v *VTEC) EventId() VTECEventId {
+	id := VTECEventId{
+		Wfo:          v.Wfo(),
+		Phenomena:    v.Phenomena,
+		Significance: v.Significance,
+		ETN:          v.ETN,
+	}
+
+	if v.Begin != nil {
+		id.Year = v.Begin.Year()
+	} else if v.End != nil {
+		id.Year = v.End.Year()
+	}
+
+	return id
+}
+
+// VTECEventId identifies a single VTEC event. ETNs reset each year per office,
+// phenomena and significance
+type VTECEventId struct {
+	Wfo          string `json:"wfo"`
+	Year         int    `json:"year"`
+	Phenomena    string `json:"phenomena"`
+	Significance string `json:"significance"`
+	ETN          int    `json:"eventid"`
+}
+
+func (id VTECEventId) values() url.Values {
+	v := url.Values{}
+
+	v.Add("wfo", id.Wfo)
+	v.Add("year", strconv.Itoa(id.Year))
+	v.Add("phenomena", id.Phenomena)
+	v.Add("significance", id.Significance)
+	v.Add("etn", strconv.Itoa(id.ETN))
+
+	return v
+}
+
+// VTECEvent is a summary of a VTEC event as listed by IEM
+type VTECEvent struct {
+	VTECEventId
+
+	Issue        time.Time `json:"issue"`
+	Expire       time.Time `json:"expire"`
+	ProductIssue time.Time `json:"product_issue"`
+
+	Locations string  `json:"locations"` // Counties/zones included in the event
+	Area      float64 `json:"area"`      // Area of the event [sq km]
+	URI       string  `json:"uri"`       // IEM VTEC browser path for the event
+}
+
+// ActiveAt reports whether t is between the event's issue and expire times
+func (e *VTECEvent) ActiveAt(t time.Time) bool {
+	return !t.Before(e.Issue) && t.Before(e.Expire)
+}
+
+// VTECEventDetail is the text of a VTEC event's issuing product and its follow up statements
+type VTECEventDetail struct {
+	VTECEventId
+
+	Report  string   `json:"report"`  // Text of the product that issued the event
+	Updates []string `json:"updates"` // Text of the follow up statements (SVS, etc)
+}
+
+// VTECEventPolygon is the storm based polygon of a VTEC event
+type VTECEventPolygon struct {
+	VTECEventId
+
+	Geometry MultiPolygon `json:"geometry"`
+}
+
+// ContainsStation reports whether the station is inside the event's polygon
+func (p *VTECEventPolygon) ContainsStation(station *Station) bool {
+	return p.Geometry.ContainsStation(station)
+}
+
+type iemVTECEventsJsonResponse struct {
+	Wfo    string       `json:"wfo"`
+	Year   int          `json:"year"`
+	Events []*VTECEvent `json:"events"`
+}
+
+type iemVTECEventJsonResponse struct {
+	Report struct {
+		Text string `json:"text"`
+	} `json:"report"`
+	SVS []struct {
+		Text string `json:"text"`
+	} `json:"svs"`
+}
+
+// VTECEventQuery filters the VTEC events listed for a WFO and year.
+// Empty Phenomena or Significance match every event
+type VTECEventQuery struct {
+	Wfo          string
+	Year         int
+	Phenomena    string
+	Significance string
+}
+
+type VTECService interface {
+	GetEvents(ctx context.Context, query VTECEventQuery) ([]*VTECEvent, error)
+	GetEvent(ctx context.Context, id VTECEventId) (*VTECEventDetail, error)
+	GetEventPolygon(ctx context.Context, id VTECEventId) (*VTECEventPolygon, error)
+}
+
+type IEMVTECService struct {
+	client *Client
+}
+
+func (s *IEMVTECService) GetEvents(ctx context.Context, query VTECEventQuery) ([]*VTECEvent, error) {
+	v := url.Values{}
+	v.Add("wfo", query.Wfo)
+	v.Add("year", strconv.Itoa(query.Year))
+
+	url := fmt.Sprintf("/json/vtec_events.py?%s", v.Encode())
+	var eventsResponse iemVTECEventsJsonResponse
+
+	err := s.client.getJson(ctx, url, &eventsResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*VTECEvent{}
+
+	for _, event := range eventsResponse.Events {
+		if query.Phenomena != "" && event.Phenomena != query.Phenomena {
+			continue
+		}
+
+		if query.Significance != "" && event.Significance != query.Significance {
+			continue
+		}
+
+		event.Wfo = query.Wfo
+		event.Year = query.Year
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (s *IEMVTECService) GetEvent(ctx context.Context, id VTECEventId) (*VTECEventDetail, error) {
+	url := fmt.Sprintf("/json/vtec_event.py?%s", id.values().Encode())
+	var eventResponse iemVTECEventJsonResponse
+
+	err := s.client.getJson(ctx, url, &eventResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &VTECEventDetail{
+		VTECEventId: id,
+		Report:      eventResponse.Report.Text,
+		Updates:     []string{},
+	}
+
+	for _, svs := range eventResponse.SVS {
+		detail.Updates = append(detail.Updates, svs.Text)
+	}
+
+	return detail, nil
+}
+
+func (s *IEMVTECService) GetEventPolygon(ctx context.Context, id VTECEventId) (*VTECEventPolygon, error) {
+	v := id.values()
+	v.Add("sbw", "1")
+
+	url := fmt.Sprintf("/geojson/vtec_event.py?%s", v.Encode())
+	var collection geoJSONFeatureCollection
+
+	err := s.client.getJson(ctx, url, &collection)
+
+	if err != nil {
+		return nil, err
+	}
+
+	polygon := &VTECEventPolygon{VTECEventId: id}
+
+	for _, feature := range collection.Features {
+		geometry, err := feature.Geometry.multiPolygon()
+
+		if err != nil {
+			return nil, err
+		}
+
+		polygon.Geometry = append(polygon.Geometry, geometry...)
+	}
+
+	return polygon, nil
+}
@@ -0,0 +1,69 @@
+package iem
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseVTEC(t *testing.T) {
+	vtec, err := ParseVTEC("/O.NEW.KDMX.TO.W.0012.230401T2200Z-230401T2245Z/")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vtec.Action != VTECNew || vtec.Office != "KDMX" || vtec.Phenomena != "TO" || vtec.Significance != "W" || vtec.ETN != 12 {
+		t.Fatalf("unexpected vtec %+v", vtec)
+	}
+
+	begin := time.Date(2023, 4, 1, 22, 0, 0, 0, time.UTC)
+
+	if vtec.Begin == nil || !vtec.Begin.Equal(begin) {
+		t.Fatalf("unexpected begin %v", vtec.Begin)
+	}
+
+	if vtec.String() != "/O.NEW.KDMX.TO.W.0012.230401T2200Z-230401T2245Z/" {
+		t.Fatalf("unexpected string %s", vtec.String())
+	}
+
+	if id := vtec.EventId(); id.Wfo != "DMX" || id.Year != 2023 {
+		t.Fatalf("unexpected event id %+v", id)
+	}
+}
+
+func TestParseVTECInEffect(t *testing.T) {
+	vtec, err := ParseVTEC("O.CON.KDMX.SV.A.0101.000000T0000Z-230401T0300Z")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vtec.Begin != nil || vtec.End == nil {
+		t.Fatalf("unexpected times %v %v", vtec.Begin, vtec.End)
+	}
+
+	if _, err := ParseVTEC("/O.NEW.KDMX.TO/"); err == nil {
+		t.Fatal("expected error for invalid vtec")
+	}
+}
+
+func TestMultiPolygonContainsStation(t *testing.T) {
+	polygon := MultiPolygon{
+		Polygon{
+			{{-97, 40}, {-96, 40}, {-96, 41}, {-97, 41}, {-97, 40}},
+			{{-96.6, 40.6}, {-96.4, 40.6}, {-96.4, 40.9}, {-96.6, 40.9}, {-96.6, 40.6}},
+		},
+	}
+
+	if !polygon.ContainsStation(&Station{Latitude: 40.8312, Longitude: -96.7633}) {
+		t.Fatal("expected LNK inside polygon")
+	}
+
+	if polygon.ContainsStation(&Station{Latitude: 40.7, Longitude: -96.5}) {
+		t.Fatal("expected point in hole to be outside polygon")
+	}
+
+	if polygon.ContainsStation(&Station{Latitude: 41.5, Longitude: -96.5}) {
+		t.Fatal("expected point outside polygon")
+	}
+}
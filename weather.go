@@ -3,23 +3,22 @@ package iem
 import (
 	"context"
 	"fmt"
-	"os"
+	"io"
 )
 
 type WeatherService interface {
 	Get(ctx context.Context, query *WeatherDataQueryBuilder) ([]*IEMWeatherData, error)
 }
 
-type TestWeatherService struct{}
-
-func (s *TestWeatherService) GetWeatherAtStation(ctx context.Context, query *WeatherDataQueryBuilder) ([]*IEMWeatherData, error) {
-	// file, err := os.Open("./data/full_weather_data.csv")
-	file, err := os.Open("./data/partial_weather_data.csv")
-	defer file.Close()
-
-	data, err := ParseWeatherData(file, query)
-
-	return data, err
+// WeatherStreamer is implemented by weather services that can stream weather
+// data, such as IEMWeatherService
+//
+//	if streamer, ok := client.Weather().(iem.WeatherStreamer); ok {
+//		reader, err := streamer.Stream(ctx, query)
+//	}
+type WeatherStreamer interface {
+	// Streams the weather data of a query. The reader must be closed
+	Stream(ctx context.Context, query *WeatherDataQueryBuilder) (*WeatherDataReader, error)
 }
 
 type IEMWeatherService struct {
@@ -35,15 +34,31 @@ func (s *IEMWeatherService) Get(ctx context.Context, query *WeatherDataQueryBuil
 
 	url := fmt.Sprintf("/cgi-bin/request/asos.py?%s", v.Encode())
 
-	body, err := s.client.get(ctx, url)
+	var weather []*IEMWeatherData
+
+	err = s.client.get(ctx, url, func(body io.Reader) (err error) {
+		weather, err = ParseWeatherData(body, query)
+		return err
+	})
+
+	return weather, err
+}
+
+// Streams the weather data of a query. The reader must be closed
+func (s *IEMWeatherService) Stream(ctx context.Context, query *WeatherDataQueryBuilder) (*WeatherDataReader, error) {
+	v, err := query.BuildUrl()
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer body.Close()
+	url := fmt.Sprintf("/cgi-bin/request/asos.py?%s", v.Encode())
 
-	weather, err := ParseWeatherData(body, query)
+	body, err := s.client.stream(ctx, url)
 
-	return weather, err
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWeatherDataReader(body, query), nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -68,50 +69,362 @@ type IEMWeatherData struct {
 	SnowDepth float64 `json:"snowdepth,omitempty"` // Snow Depth (4-group) [inch] (snowdepth)
 
 	METAR string `json:"metar,omitempty"` // Raw METAR (metar)
+
+	// Data and location columns that were observed, missing or trace amounts.
+	// Bits are the columns' indexes in weatherStateColumns
+	observed columnSet
+	missing  columnSet
+	trace    columnSet
+}
+
+// columnSet is a set of columns by their index in a list of columns
+type columnSet uint64
+
+func (s columnSet) has(i int) bool {
+	return s&(1<<i) != 0
+}
+
+func (s *columnSet) add(i int) {
+	*s |= 1 << i
+}
+
+func (s *columnSet) remove(i int) {
+	*s &^= 1 << i
+}
+
+// columnState records whether the numeric columns of a parsed record were
+// observed, missing or trace amounts. Bits are the columns' indexes in a data
+// type's list of columns
+type columnState struct {
+	observed columnSet
+	missing  columnSet
+	trace    columnSet
+}
+
+// traceValues is implemented by queries whose responses report trace amounts
+type traceValues interface {
+	isTrace(value string) bool
+}
+
+// Records the state of the column at index i from its value in a record.
+// Columns that are not in the record are missing
+func (s *columnState) parse(i int, key string, w *weatherDataIndecies, record *[]string, query missingValues) {
+	idx, ok := w.getIndex(key)
+
+	if !ok {
+		s.missing.add(i)
+		return
+	}
+
+	v := (*record)[idx]
+
+	if t, ok := query.(traceValues); ok && t.isTrace(v) {
+		s.trace.add(i)
+	} else if query.isMissingOrTrace(v) {
+		s.missing.add(i)
+	} else {
+		s.observed.add(i)
+	}
+}
+
+// Reports whether the column at index i is missing. known is false when the
+// column's state was not parsed, as for data created by hand
+func (s columnState) isMissing(i int) (missing bool, known bool) {
+	switch {
+	case s.missing.has(i):
+		return true, true
+	case s.observed.has(i), s.trace.has(i):
+		return false, true
+	}
+
+	return false, false
+}
+
+// Index of a variable in a data type's list of columns
+func variableIndex[T comparable](variables []T, variable T) (int, bool) {
+	for i, v := range variables {
+		if v == variable {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// Index of each column in weatherStateColumns
+var weatherDataColumnIndex = func() map[WeatherDataData]int {
+	index := map[WeatherDataData]int{}
+
+	for i, c := range weatherStateColumns {
+		index[c] = i
+	}
+
+	return index
+}()
+
+// Records whether a data column was observed, missing or a trace amount
+func (d *IEMWeatherData) setState(column WeatherDataData, state *columnSet) {
+	i, ok := weatherDataColumnIndex[column]
+
+	if !ok {
+		return
+	}
+
+	d.observed.remove(i)
+	d.missing.remove(i)
+	d.trace.remove(i)
+	state.add(i)
+}
+
+// IsMissing reports whether a data column is missing. Parsed observations know
+// which of their columns were missing. Otherwise, as for observations created
+// without SetFloat, SetMissing or SetTrace, zero values are missing except in
+// columns where 0 is observed (ZeroIsObserved)
+func (d *IEMWeatherData) IsMissing(column WeatherDataData) bool {
+	if i, ok := weatherDataColumnIndex[column]; ok {
+		switch {
+		case d.missing.has(i):
+			return true
+		case d.observed.has(i), d.trace.has(i):
+			return false
+		}
+	}
+
+	if column == PeakWindTime {
+		return d.PeakWindTime == nil
+	}
+
+	if text, ok := d.Text(column); ok {
+		return text == ""
+	}
+
+	value, _ := d.Float(column)
+
+	return value == 0 && !column.ZeroIsObserved()
+}
+
+// IsTrace reports whether a data column is a trace amount. Traces have a value of 0
+func (d *IEMWeatherData) IsTrace(column WeatherDataData) bool {
+	i, ok := weatherDataColumnIndex[column]
+
+	return ok && d.trace.has(i)
+}
+
+// SetMissing clears the value of a data column and marks it missing
+func (d *IEMWeatherData) SetMissing(column WeatherDataData) {
+	if field := d.floatField(column); field != nil {
+		*field = 0
+	}
+
+	if field := d.textField(column); field != nil {
+		*field = ""
+	}
+
+	if column == PeakWindTime {
+		d.PeakWindTime = nil
+	}
+
+	d.setState(column, &d.missing)
+}
+
+// SetTrace sets a numeric data column to a trace amount. False when the column is not numeric
+func (d *IEMWeatherData) SetTrace(column WeatherDataData) bool {
+	field := d.floatField(column)
+
+	if field == nil {
+		return false
+	}
+
+	*field = 0
+	d.setState(column, &d.trace)
+
+	return true
+}
+
+// Float returns the value of a numeric data column. False when the column is not numeric
+func (d *IEMWeatherData) Float(column WeatherDataData) (float64, bool) {
+	field := d.floatField(column)
+
+	if field == nil {
+		return 0, false
+	}
+
+	return *field, true
+}
+
+// SetFloat sets the value of a numeric data column and marks it observed, so a
+// value of 0 is not missing. False when the column is not numeric
+func (d *IEMWeatherData) SetFloat(column WeatherDataData, value float64) bool {
+	field := d.floatField(column)
+
+	if field == nil {
+		return false
+	}
+
+	*field = value
+	d.setState(column, &d.observed)
+
+	return true
+}
+
+func (d *IEMWeatherData) floatField(column WeatherDataData) *float64 {
+	switch column {
+	case TempF:
+		return &d.TemperatureF
+	case TempC:
+		return &d.TemperatureC
+	case DewPointF:
+		return &d.DewPointF
+	case DewPointC:
+		return &d.DewPointC
+	case RelativeHumidity:
+		return &d.RelativeHumidity
+	case Feel:
+		return &d.Feel
+	case WindDirection:
+		return &d.WindDirection
+	case WindSpeedKnots:
+		return &d.WindSpeedKnots
+	case WindSpeedMPH:
+		return &d.WindSpeedMPH
+	case Altimeter:
+		return &d.Altimeter
+	case SeaLevelPressure:
+		return &d.SeaLevelPressure
+	case PrecipMM:
+		return &d.PrecipMM
+	case PrecipInch:
+		return &d.PrecipInch
+	case Visibility:
+		return &d.Visibility
+	case WindGustKnots:
+		return &d.WindGustKnots
+	case WindGustMPH:
+		return &d.WindGustMPH
+	case CloudHeightL1:
+		return &d.CloudHeightL1
+	case CloudHeightL2:
+		return &d.CloudHeightL2
+	case CloudHeightL3:
+		return &d.CloudHeightL3
+	case IceAccretion1HR:
+		return &d.IceAccretion1HR
+	case IceAccretion3HR:
+		return &d.IceAccretion3HR
+	case IceAccretion6HR:
+		return &d.IceAccretion6HR
+	case PeakWindGustKnots:
+		return &d.PeakWindGustKnots
+	case PeakWindGustMPH:
+		return &d.PeakWindGustMPH
+	case PeakWindDirection:
+		return &d.PeakWindDirection
+	case SnowDepth:
+		return &d.SnowDepth
+	case Longitude:
+		return &d.Lon
+	case Latitude:
+		return &d.Lat
+	}
+
+	return nil
 }
 
 // Parse weather data from a io.Reader that reads CSV data based on a WeatherDataQueryBuilder
 func ParseWeatherData(reader io.Reader, query *WeatherDataQueryBuilder) ([]*IEMWeatherData, error) {
-	csvReader := csv.NewReader(reader)
+	data := []*IEMWeatherData{}
 
-	csvReader.ReuseRecord = true
+	err := readCsvReaderRecords(query.csvReader(reader), func(keyIndecies *weatherDataIndecies, csvRecord *[]string) error {
+		weatherData, err := keyIndecies.csvRecordToWeatherData(csvRecord, query)
 
-	data := []*IEMWeatherData{}
+		if err != nil {
+			return err
+		}
+
+		data = append(data, weatherData)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Reads CSV data with a header row and calls parse with each following record
+// and the index of each header
+func readCsvRecords(reader io.Reader, parse func(keyIndecies *weatherDataIndecies, csvRecord *[]string) error) error {
+	return readCsvReaderRecords(csv.NewReader(reader), parse)
+}
+
+// Indexes the columns of a CSV header. IEM scripts report errors such as an
+// unknown station as plain text with a 200, so a header without a station
+// column is an error rather than an empty result
+func csvHeaderIndecies(header []string) (weatherDataIndecies, error) {
 	keyIndecies := weatherDataIndecies(make(map[string]int))
 
+	for i, key := range header {
+		keyIndecies[key] = i
+	}
+
+	if _, ok := keyIndecies["station"]; !ok {
+		return nil, fmt.Errorf("unexpected response %q", strings.Join(header, ","))
+	}
+
+	return keyIndecies, nil
+}
+
+// Reads records like readCsvRecords from a configured csv.Reader
+func readCsvReaderRecords(csvReader *csv.Reader, parse func(keyIndecies *weatherDataIndecies, csvRecord *[]string) error) error {
+	csvReader.ReuseRecord = true
+
+	var keyIndecies weatherDataIndecies
+
 	r := 0
 
+	// Declared once since its address escapes to parse
+	var csvRecord []string
+	var err error
+
 	for {
-		csvRecord, err := csvReader.Read()
+		csvRecord, err = csvReader.Read()
 
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if r == 0 {
 			r++
 
-			for i, header := range csvRecord {
-				keyIndecies[header] = i
+			if keyIndecies, err = csvHeaderIndecies(csvRecord); err != nil {
+				return err
 			}
 
 			continue
 		}
 
-		weatherData, err := keyIndecies.csvRecordToWeatherData(&csvRecord, query)
-
-		if err != nil {
-			return nil, err
+		if err = parse(&keyIndecies, &csvRecord); err != nil {
+			return err
 		}
-
-		data = append(data, weatherData)
 	}
 
-	return data, nil
+	return nil
+}
+
+// missingValues reports whether a CSV value represents missing or trace data
+type missingValues interface {
+	isMissingOrTrace(value string) bool
+}
+
+// timeLocation is implemented by queries that request times in a timezone
+type timeLocation interface {
+	Location() *time.Location
 }
 
 type weatherDataIndecies map[string]int
@@ -128,50 +441,67 @@ func (w *weatherDataIndecies) csvRecordToWeatherData(record *[]string, query *We
 
 	err := w.setString("station", record, &data.Station, query)
 	err = w.setTime("valid", record, &data.Time, query)
-	err = w.setFloat("lon", record, &data.Lon, query)
-	err = w.setFloat("lat", record, &data.Lat, query)
 	err = w.setString("elevation", record, &data.Elevation, query)
-	err = w.setFloat("tmpf", record, &data.TemperatureF, query)
-	err = w.setFloat("tmpc", record, &data.TemperatureC, query)
-	err = w.setFloat("dwpf", record, &data.DewPointF, query)
-	err = w.setFloat("dwpc", record, &data.DewPointC, query)
-	err = w.setFloat("relh", record, &data.RelativeHumidity, query)
-	err = w.setFloat("feel", record, &data.Feel, query)
-	err = w.setFloat("drct", record, &data.WindDirection, query)
-	err = w.setFloat("sknt", record, &data.WindSpeedKnots, query)
-	err = w.setFloat("sped", record, &data.WindSpeedMPH, query)
-	err = w.setFloat("gust", record, &data.WindGustKnots, query)
-	err = w.setFloat("gust_mph", record, &data.WindGustMPH, query)
-	err = w.setFloat("peak_wind_gust", record, &data.PeakWindGustKnots, query)
-	err = w.setFloat("peak_wind_gust_mph", record, &data.PeakWindGustMPH, query)
-	err = w.setFloat("peak_wind_drct", record, &data.PeakWindDirection, query)
-	err = w.setTime("peak_wind_time", record, &data.PeakWindTime, query)
-	err = w.setFloat("alti", record, &data.Altimeter, query)
-	err = w.setFloat("mslp", record, &data.SeaLevelPressure, query)
-	err = w.setFloat("p01m", record, &data.PrecipMM, query)
-	err = w.setFloat("p01i", record, &data.PrecipInch, query)
-	err = w.setFloat("vsby", record, &data.Visibility, query)
-	err = w.setString("skyc1", record, &data.CloudCoverageL1, query)
-	err = w.setString("skyc2", record, &data.CloudCoverageL2, query)
-	err = w.setString("skyc3", record, &data.CloudCoverageL3, query)
-	err = w.setFloat("skyl1", record, &data.CloudHeightL1, query)
-	err = w.setFloat("skyl2", record, &data.CloudHeightL2, query)
-	err = w.setFloat("skyl3", record, &data.CloudHeightL3, query)
-	err = w.setString("wxcodes", record, &data.PresentWeatherCodes, query)
-	err = w.setFloat("ice_accretion_1hr", record, &data.IceAccretion1HR, query)
-	err = w.setFloat("ice_accretion_3hr", record, &data.IceAccretion3HR, query)
-	err = w.setFloat("ice_accretion_6hr", record, &data.IceAccretion6HR, query)
-	err = w.setFloat("snowdepth", record, &data.SnowDepth, query)
-	err = w.setString("metar", record, &data.METAR, query)
 
 	if err != nil {
 		return nil, err
 	}
 
+	for i, column := range weatherStateColumns {
+		w.setData(i, column, record, data, query)
+	}
+
 	return data, nil
 }
 
-func (w *weatherDataIndecies) setString(key string, record *[]string, data *string, query *WeatherDataQueryBuilder) error {
+// Sets a data column of an observation and records whether it was observed,
+// missing or a trace amount. Values that do not parse and columns that are not
+// in the response are missing
+func (w *weatherDataIndecies) setData(i int, column WeatherDataData, record *[]string, data *IEMWeatherData, query *WeatherDataQueryBuilder) {
+	idx, ok := w.getIndex(string(column))
+
+	if !ok {
+		data.missing.add(i)
+		return
+	}
+
+	v := (*record)[idx]
+
+	switch v {
+	case query.missing.text():
+		data.missing.add(i)
+		return
+	case query.trace.text():
+		data.trace.add(i)
+		return
+	}
+
+	if column == PeakWindTime {
+		t, err := time.ParseInLocation(weatherDataTimeLayout, v, query.Location())
+
+		if err != nil {
+			data.missing.add(i)
+			return
+		}
+
+		data.PeakWindTime = &t
+	} else if field := data.textField(column); field != nil {
+		*field = v
+	} else if field := data.floatField(column); field != nil {
+		f, err := strconv.ParseFloat(v, 64)
+
+		if err != nil {
+			data.missing.add(i)
+			return
+		}
+
+		*field = f
+	}
+
+	data.observed.add(i)
+}
+
+func (w *weatherDataIndecies) setString(key string, record *[]string, data *string, query missingValues) error {
 	idx, ok := w.getIndex(key)
 	if !ok {
 		return nil
@@ -187,7 +517,7 @@ func (w *weatherDataIndecies) setString(key string, record *[]string, data *stri
 	return nil
 }
 
-func (w *weatherDataIndecies) setFloat(key string, record *[]string, data *float64, query *WeatherDataQueryBuilder) error {
+func (w *weatherDataIndecies) setFloat(key string, record *[]string, data *float64, query missingValues) error {
 	idx, ok := w.getIndex(key)
 	if !ok {
 		return nil
@@ -210,7 +540,11 @@ func (w *weatherDataIndecies) setFloat(key string, record *[]string, data *float
 	return nil
 }
 
-func (w *weatherDataIndecies) setTime(key string, record *[]string, data **time.Time, query *WeatherDataQueryBuilder) error {
+func (w *weatherDataIndecies) setTime(key string, record *[]string, data **time.Time, query missingValues) error {
+	return w.setTimeLayout(key, "2006-01-02 15:04", record, data, query)
+}
+
+func (w *weatherDataIndecies) setTimeLayout(key string, layout string, record *[]string, data **time.Time, query missingValues) error {
 	idx, ok := w.getIndex(key)
 	if !ok {
 		return nil
@@ -222,7 +556,13 @@ func (w *weatherDataIndecies) setTime(key string, record *[]string, data **time.
 		return nil
 	}
 
-	t, err := time.Parse("2006-01-02 15:04", v)
+	location := time.UTC
+
+	if l, ok := query.(timeLocation); ok {
+		location = l.Location()
+	}
+
+	t, err := time.ParseInLocation(layout, v, location)
 
 	if err != nil {
 		return fmt.Errorf("error parsing %s [%w]", key, err)
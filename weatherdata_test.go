@@ -29,3 +29,28 @@ func BenchmarkParseWeatherData(b *testing.B) {
 		ParseWeatherData(r, query)
 	}
 }
+
+func TestParseWeatherDataFormats(t *testing.T) {
+	tests := []struct {
+		format WeatherDataQueryFormat
+		body   string
+	}{
+		{OnlyComma, "station,valid,tmpf,p01i\nLNK,2023-10-04 01:10,68.00,T\n"},
+		{Comma, "#DEBUG: Format Typ    -> comma\n#DEBUG: Time Period   -> 2023-10-04 2023-10-05\nstation,valid,tmpf,p01i\nLNK,2023-10-04 01:10,68.00,T\n"},
+		{OnlyTDF, "station\tvalid\ttmpf\tp01i\nLNK\t2023-10-04 01:10\t68.00\tT\n"},
+		{TDF, "#DEBUG: Format Typ    -> tdf\nstation\tvalid\ttmpf\tp01i\nLNK\t2023-10-04 01:10\t68.00\tT\n"},
+	}
+
+	for _, test := range tests {
+		query := NewWeatherDataQuery().Data(TempF, PrecipInch).Format(test.format)
+		data, err := ParseWeatherData(strings.NewReader(test.body), query)
+
+		if err != nil {
+			t.Fatalf("%s: %s", test.format, err)
+		}
+
+		if len(data) != 1 || data[0].Station != "LNK" || data[0].TemperatureF != 68 || !data[0].IsTrace(PrecipInch) {
+			t.Errorf("%s: unexpected data %+v", test.format, data)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package iem
 
 import (
+	"encoding/csv"
 	"fmt"
+	"io"
 	"net/url"
 	"strconv"
 	"time"
@@ -99,6 +101,37 @@ func requiredError(prop string) WeatherDataQueryBuilderError {
 	}
 }
 
+// QueryBuilderError is returned when building the url query of the
+// non ASOS query builders
+type QueryBuilderError struct {
+	msg string
+}
+
+func (err QueryBuilderError) Error() string {
+	return err.msg
+}
+
+func builderRequiredError(builder string, prop string) QueryBuilderError {
+	return QueryBuilderError{
+		msg: fmt.Sprintf("%s: %s property is required", builder, prop),
+	}
+}
+
+func loadTimezone(tz string) *time.Location {
+	// time.UTC parses times without looking up zones
+	if tz == defaultTimeZone {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(tz)
+
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
 // WeatherDataQueryBuilder represents the url query sent to the IEM API
 // when requesting weather data for stations
 type WeatherDataQueryBuilder struct {
@@ -123,6 +156,9 @@ type WeatherDataQueryBuilder struct {
 	// Timezone used for dates
 	tz string
 
+	// Location of tz, loaded when tz is set
+	location *time.Location
+
 	// onlycomma
 	// onlytdf
 	// comma
@@ -165,6 +201,7 @@ type WeatherDataQueryBuilder struct {
 func NewWeatherDataQuery() *WeatherDataQueryBuilder {
 	return &WeatherDataQueryBuilder{
 		tz:         defaultTimeZone,
+		location:   loadTimezone(defaultTimeZone),
 		latlon:     defaultLatLon,
 		elev:       defaultElevation,
 		missing:    defaultMissing,
@@ -179,17 +216,37 @@ func NewWeatherDataQuery() *WeatherDataQueryBuilder {
 }
 
 func (b *WeatherDataQueryBuilder) isMissingOrTrace(value string) bool {
-	if value == string(b.missing) {
+	if value == b.missing.text() {
 		return true
 	}
 
-	if value == string(b.trace) {
+	if value == b.trace.text() {
 		return true
 	}
 
 	return false
 }
 
+// Delimiter of the query's format
+func (b *WeatherDataQueryBuilder) delimiter() rune {
+	switch b.format {
+	case TDF, OnlyTDF:
+		return '\t'
+	}
+
+	return ','
+}
+
+// Creates a csv.Reader for data in the query's format. The DEBUG headers of
+// the comma and tdf formats are skipped
+func (b *WeatherDataQueryBuilder) csvReader(reader io.Reader) *csv.Reader {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = b.delimiter()
+	csvReader.Comment = '#'
+
+	return csvReader
+}
+
 // Appends stations to builder.station
 func (b *WeatherDataQueryBuilder) Stations(stations ...string) *WeatherDataQueryBuilder {
 	for _, s := range stations {
@@ -223,10 +280,21 @@ func (b *WeatherDataQueryBuilder) End(t time.Time) *WeatherDataQueryBuilder {
 // Sets query builder end date (defaults to Etc/UTC)
 func (b *WeatherDataQueryBuilder) Timezone(tz string) *WeatherDataQueryBuilder {
 	b.tz = tz
+	b.location = loadTimezone(tz)
 	return b
 }
 
-// Sets query builder format (defaults to Etc/UTC)
+// Location of the query's timezone that returned times are parsed in.
+// UTC when the timezone is not known
+func (b *WeatherDataQueryBuilder) Location() *time.Location {
+	if b.location == nil {
+		return loadTimezone(b.tz)
+	}
+
+	return b.location
+}
+
+// Sets query builder format (defaults to onlycomma)
 func (b *WeatherDataQueryBuilder) Format(format WeatherDataQueryFormat) *WeatherDataQueryBuilder {
 	b.format = format
 	return b
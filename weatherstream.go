@@ -0,0 +1,92 @@
+package iem
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// WeatherDataReader reads weather data one observation at a time so large
+// queries do not need to be held in memory
+type WeatherDataReader struct {
+	csvReader   *csv.Reader
+	closer      io.Closer
+	query       *WeatherDataQueryBuilder
+	keyIndecies weatherDataIndecies
+
+	// Observations read instead of CSV when created by NewWeatherDataSliceReader
+	data []*IEMWeatherData
+}
+
+// Creates a WeatherDataReader from a io.Reader that reads CSV data based on a WeatherDataQueryBuilder
+func NewWeatherDataReader(reader io.Reader, query *WeatherDataQueryBuilder) *WeatherDataReader {
+	csvReader := query.csvReader(reader)
+	csvReader.ReuseRecord = true
+
+	r := &WeatherDataReader{
+		csvReader: csvReader,
+		query:     query,
+	}
+
+	if closer, ok := reader.(io.Closer); ok {
+		r.closer = closer
+	}
+
+	return r
+}
+
+// Creates a WeatherDataReader that reads observations already in memory, such
+// as a mock service streaming scripted observations
+func NewWeatherDataSliceReader(data []*IEMWeatherData, query *WeatherDataQueryBuilder) *WeatherDataReader {
+	return &WeatherDataReader{
+		query: query,
+		data:  append([]*IEMWeatherData{}, data...),
+	}
+}
+
+// Query the data was requested with
+func (r *WeatherDataReader) Query() *WeatherDataQueryBuilder {
+	return r.query
+}
+
+// Next reads the next observation. Returns io.EOF when there are no more observations
+func (r *WeatherDataReader) Next() (*IEMWeatherData, error) {
+	if r.csvReader == nil {
+		if len(r.data) == 0 {
+			return nil, io.EOF
+		}
+
+		d := r.data[0]
+		r.data = r.data[1:]
+
+		return d, nil
+	}
+
+	if r.keyIndecies == nil {
+		header, err := r.csvReader.Read()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if r.keyIndecies, err = csvHeaderIndecies(header); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := r.csvReader.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.keyIndecies.csvRecordToWeatherData(&record, r.query)
+}
+
+// Close closes the underlying reader when it is a io.Closer
+func (r *WeatherDataReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	return r.closer.Close()
+}
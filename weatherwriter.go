@@ -0,0 +1,251 @@
+package iem
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Data columns in the order asos.py returns them
+var weatherDataColumns = []WeatherDataData{
+	TempF,
+	TempC,
+	DewPointF,
+	DewPointC,
+	RelativeHumidity,
+	Feel,
+	WindDirection,
+	WindSpeedKnots,
+	WindSpeedMPH,
+	Altimeter,
+	SeaLevelPressure,
+	PrecipMM,
+	PrecipInch,
+	Visibility,
+	WindGustKnots,
+	WindGustMPH,
+	CloudCoverageL1,
+	CloudCoverageL2,
+	CloudCoverageL3,
+	CloudHeightL1,
+	CloudHeightL2,
+	CloudHeightL3,
+	PresentWeatherCodes,
+	IceAccretion1HR,
+	IceAccretion3HR,
+	IceAccretion6HR,
+	PeakWindGustKnots,
+	PeakWindGustMPH,
+	PeakWindDirection,
+	PeakWindTime,
+	SnowDepth,
+	METAR,
+}
+
+// Location columns returned with LatLon(true). They are not data columns and
+// can not be requested with Data, but IsMissing reports whether an
+// observation's location is known
+const (
+	Longitude WeatherDataData = "lon"
+	Latitude  WeatherDataData = "lat"
+)
+
+// Columns whose state is tracked, the data columns followed by the location columns
+var weatherStateColumns = append(append([]WeatherDataData{}, weatherDataColumns...), Longitude, Latitude)
+
+// Columns where 0 is a real observation rather than a missing value
+var zeroObservedColumns = map[WeatherDataData]bool{
+	WindDirection:  true,
+	WindSpeedKnots: true,
+	WindSpeedMPH:   true,
+	PrecipMM:       true,
+	PrecipInch:     true,
+}
+
+// ZeroIsObserved reports whether 0 in the column is a routinely observed value
+// rather than a missing value that parsed as 0
+func (c WeatherDataData) ZeroIsObserved() bool {
+	return zeroObservedColumns[c]
+}
+
+const weatherDataTimeLayout = "2006-01-02 15:04"
+
+// Columns are the data columns returned by the query in asos.py's order
+func (b *WeatherDataQueryBuilder) Columns() []WeatherDataData {
+	requested := map[WeatherDataData]bool{}
+
+	for _, d := range b.data {
+		requested[d] = true
+	}
+
+	columns := []WeatherDataData{}
+
+	for _, c := range weatherDataColumns {
+		if requested[All] || requested[c] {
+			columns = append(columns, c)
+		}
+	}
+
+	return columns
+}
+
+// MetadataColumns are the station metadata columns returned by the query (lon, lat, elevation)
+func (b *WeatherDataQueryBuilder) MetadataColumns() []string {
+	columns := []string{}
+
+	if b.latlon {
+		columns = append(columns, "lon", "lat")
+	}
+
+	if b.elev {
+		columns = append(columns, "elevation")
+	}
+
+	return columns
+}
+
+// Text of the missing value encoding as it appears in CSV data
+func (m WeatherDataQueryMissing) text() string {
+	switch m {
+	case MissingEmpty:
+		return ""
+	}
+
+	return string(m)
+}
+
+// Text of the trace value encoding as it appears in CSV data
+func (t WeatherDataQueryTrace) text() string {
+	switch t {
+	case TraceEmpty:
+		return ""
+	}
+
+	return string(t)
+}
+
+// Text returns the value of a text data column (skyc1, wxcodes, metar, etc).
+// False when the column is not text
+func (d *IEMWeatherData) Text(column WeatherDataData) (string, bool) {
+	field := d.textField(column)
+
+	if field == nil {
+		return "", false
+	}
+
+	return *field, true
+}
+
+func (d *IEMWeatherData) textField(column WeatherDataData) *string {
+	switch column {
+	case CloudCoverageL1:
+		return &d.CloudCoverageL1
+	case CloudCoverageL2:
+		return &d.CloudCoverageL2
+	case CloudCoverageL3:
+		return &d.CloudCoverageL3
+	case PresentWeatherCodes:
+		return &d.PresentWeatherCodes
+	case METAR:
+		return &d.METAR
+	}
+
+	return nil
+}
+
+// WriteWeatherData writes observations as CSV with the column names and order
+// asos.py uses for the query, so it can be read by ParseWeatherData and tools
+// that read IEM's CSV. Values are tab delimited for the tdf formats and DEBUG
+// headers are not written. Times are written in the query's timezone and missing
+// values with the query's missing encoding.
+//
+// Trace amounts are written with the query's trace encoding. Columns are
+// missing or traces as reported by IsMissing and IsTrace
+func WriteWeatherData(w io.Writer, data []*IEMWeatherData, query *WeatherDataQueryBuilder) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = query.delimiter()
+	columns := query.Columns()
+	location := query.Location()
+	missing := query.missing.text()
+	trace := query.trace.text()
+
+	header := append([]string{"station", "valid"}, query.MetadataColumns()...)
+
+	for _, c := range columns {
+		header = append(header, string(c))
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return missing
+		}
+
+		return t.In(location).Format(weatherDataTimeLayout)
+	}
+
+	orMissing := func(value string) string {
+		if value == "" {
+			return missing
+		}
+
+		return value
+	}
+
+	record := make([]string, 0, len(header))
+
+	for _, d := range data {
+		record = append(record[:0], d.Station, formatTime(d.Time))
+
+		if query.latlon {
+			for _, c := range []WeatherDataData{Longitude, Latitude} {
+				if d.IsMissing(c) {
+					record = append(record, missing)
+				} else {
+					value, _ := d.Float(c)
+					record = append(record, strconv.FormatFloat(value, 'f', 4, 64))
+				}
+			}
+		}
+
+		if query.elev {
+			record = append(record, orMissing(d.Elevation))
+		}
+
+		for _, c := range columns {
+			switch {
+			case d.IsTrace(c):
+				record = append(record, trace)
+				continue
+			case d.IsMissing(c):
+				record = append(record, missing)
+				continue
+			}
+
+			if c == PeakWindTime {
+				record = append(record, formatTime(d.PeakWindTime))
+				continue
+			}
+
+			if text, ok := d.Text(c); ok {
+				record = append(record, text)
+				continue
+			}
+
+			value, _ := d.Float(c)
+			record = append(record, strconv.FormatFloat(value, 'f', 2, 64))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,166 @@
+package iem
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func roundTripWeatherData(t *testing.T, fixture string, query *WeatherDataQueryBuilder) (string, string) {
+	t.Helper()
+
+	original, err := os.ReadFile(fixture)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseWeatherData(bytes.NewReader(original), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written bytes.Buffer
+
+	if err := WriteWeatherData(&written, parsed, query); err != nil {
+		t.Fatal(err)
+	}
+
+	reparsed, err := ParseWeatherData(bytes.NewReader(written.Bytes()), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(parsed, reparsed) {
+		t.Errorf("round trip of %s changed the data", fixture)
+	}
+
+	return string(original), written.String()
+}
+
+// Rows are identical to IEM's output
+func assertSameRows(t *testing.T, original string, written string) {
+	t.Helper()
+
+	originalRows := strings.Split(strings.TrimSpace(original), "\n")
+	writtenRows := strings.Split(strings.TrimSpace(written), "\n")
+
+	if len(originalRows) != len(writtenRows) {
+		t.Fatalf("expected %d rows, got %d", len(originalRows), len(writtenRows))
+	}
+
+	for i, row := range originalRows {
+		if row != writtenRows[i] {
+			t.Errorf("row %d differs\n%s\n%s", i, row, writtenRows[i])
+		}
+	}
+}
+
+func TestWriteWeatherDataAllColumns(t *testing.T) {
+	query := NewWeatherDataQuery().Data(All).LatLon(true).Elevation(true)
+
+	original, written := roundTripWeatherData(t, "data/full_weather_data.csv", query)
+
+	assertSameRows(t, original, written)
+}
+
+func TestWriteWeatherDataRequestedColumns(t *testing.T) {
+	// Columns are written in asos.py's order regardless of the requested order
+	query := NewWeatherDataQuery().Data(PrecipInch, TempF, SeaLevelPressure, TempC, WindDirection, RelativeHumidity)
+
+	original, written := roundTripWeatherData(t, "data/partial_weather_data.csv", query)
+
+	assertSameRows(t, original, written)
+}
+
+func TestWriteWeatherDataEmptyMissing(t *testing.T) {
+	query := NewWeatherDataQuery().Data(TempF, SeaLevelPressure).Missing(MissingEmpty)
+	data, err := ParseWeatherData(strings.NewReader("station,valid,tmpf,mslp\nLNK,2023-10-04 01:10,68.00,\n"), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written bytes.Buffer
+
+	if err := WriteWeatherData(&written, data, query); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(written.String(), "LNK,2023-10-04 01:10,68.00,\n") {
+		t.Errorf("unexpected output %q", written.String())
+	}
+}
+
+func TestWriteWeatherDataTraceAndZero(t *testing.T) {
+	query := NewWeatherDataQuery().Data(TempF, DewPointF, Visibility, PrecipInch).Missing(MissingNull).Trace(TraceFloat)
+	data, err := ParseWeatherData(strings.NewReader("station,valid,tmpf,dwpf,p01i,vsby\nLNK,2023-01-04 01:10,0.00,-5.00,0.0001,0.00\n"), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !data[0].IsTrace(PrecipInch) || data[0].IsMissing(TempF) || data[0].IsMissing(Visibility) {
+		t.Errorf("expected a trace and observed zeros")
+	}
+
+	// Columns that are not in the response are missing, even where 0 is observed
+	if !data[0].IsMissing(PrecipMM) || !data[0].IsMissing(WindSpeedKnots) {
+		t.Errorf("expected columns not in the response to be missing")
+	}
+
+	data[0].SetMissing(DewPointF)
+
+	var written bytes.Buffer
+
+	if err := WriteWeatherData(&written, data, query); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(written.String(), "LNK,2023-01-04 01:10,0.00,null,0.0001,0.00\n") {
+		t.Errorf("unexpected output %q", written.String())
+	}
+}
+
+func TestIsMissingWithoutState(t *testing.T) {
+	data := &IEMWeatherData{}
+
+	if !data.IsMissing(TempF) || data.IsMissing(PrecipInch) || !data.IsMissing(METAR) {
+		t.Error("expected zero values to be missing except in zero observed columns")
+	}
+
+	data.SetFloat(TempF, 0)
+
+	if data.IsMissing(TempF) {
+		t.Error("expected a set 0 to be observed")
+	}
+
+	data.SetTrace(PrecipInch)
+
+	if !data.IsTrace(PrecipInch) || data.IsMissing(PrecipInch) {
+		t.Error("expected a trace")
+	}
+}
+
+func TestWriteWeatherDataTDF(t *testing.T) {
+	query := NewWeatherDataQuery().Data(TempF, PrecipInch).Format(OnlyTDF)
+	data, err := ParseWeatherData(strings.NewReader("station\tvalid\ttmpf\tp01i\nLNK\t2023-10-04 01:10\t68.00\tT\n"), query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written bytes.Buffer
+
+	if err := WriteWeatherData(&written, data, query); err != nil {
+		t.Fatal(err)
+	}
+
+	if written.String() != "station\tvalid\ttmpf\tp01i\nLNK\t2023-10-04 01:10\t68.00\tT\n" {
+		t.Errorf("unexpected output %q", written.String())
+	}
+}
@@ -0,0 +1,259 @@
+package iem
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// WindRoseOptions configures how observations are binned into a wind rose
+type WindRoseOptions struct {
+	Sectors   int          // Number of direction sectors, the first is centered on north
+	SpeedBins []float64    // Lower edge of each speed bin [knots], ascending. The last bin is open ended. Speeds from CalmKnots to the first edge get a bin of their own
+	CalmKnots float64      // Speeds below this are calm [knots]
+	Months    []time.Month // Only include observations in these months, all when empty
+	Hours     []int        // Only include observations in these hours (0-23), all when empty
+}
+
+// DefaultWindRoseOptions are the sectors, speed bins and calm threshold IEM
+// uses for its wind roses
+func DefaultWindRoseOptions() WindRoseOptions {
+	return WindRoseOptions{
+		Sectors:   16,
+		SpeedBins: []float64{2, 5, 7, 10, 15, 20},
+		CalmKnots: 2,
+	}
+}
+
+// WindRoseSpeedBin is a range of wind speeds [knots]. Max is 0 for the open ended last bin
+type WindRoseSpeedBin struct {
+	Label string  `json:"label"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max,omitempty"`
+}
+
+// WindRoseSector is the wind observed from a range of directions. Counts and
+// Percents are indexed by WindRose.SpeedBins
+type WindRoseSector struct {
+	Label     string    `json:"label"`     // N, NNE, etc or the center direction for uncommon sector counts
+	Direction float64   `json:"direction"` // Center direction [deg]
+	Counts    []int     `json:"counts"`
+	Percents  []float64 `json:"percents"` // Percent of all counted observations, including calm
+}
+
+// WindRose is a frequency table of wind observations by direction sector and speed bin
+type WindRose struct {
+	SpeedBins   []WindRoseSpeedBin `json:"speed_bins"`
+	Sectors     []*WindRoseSector  `json:"sectors"`
+	Calm        int                `json:"calm"`
+	CalmPercent float64            `json:"calm_percent"`
+	Total       int                `json:"total"`   // Observations counted
+	Skipped     int                `json:"skipped"` // Observations filtered out or with a missing wind speed or direction
+}
+
+var compassPoints = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+func windRoseSectorLabel(sectors int, i int, direction float64) string {
+	if sectors <= len(compassPoints) && len(compassPoints)%sectors == 0 {
+		return compassPoints[i*len(compassPoints)/sectors]
+	}
+
+	return strconv.FormatFloat(direction, 'f', -1, 64)
+}
+
+func windRoseSpeedBins(edges []float64) []WindRoseSpeedBin {
+	bins := make([]WindRoseSpeedBin, len(edges))
+
+	for i, min := range edges {
+		bins[i].Min = min
+
+		if i == len(edges)-1 {
+			bins[i].Label = fmt.Sprintf("%g+", min)
+		} else {
+			bins[i].Max = edges[i+1]
+			bins[i].Label = fmt.Sprintf("%g-%g", min, edges[i+1])
+		}
+	}
+
+	return bins
+}
+
+func (o WindRoseOptions) includes(t *time.Time) bool {
+	if len(o.Months) == 0 && len(o.Hours) == 0 {
+		return true
+	}
+
+	if t == nil {
+		return false
+	}
+
+	if len(o.Months) > 0 && !containsValue(o.Months, t.Month()) {
+		return false
+	}
+
+	if len(o.Hours) > 0 && !containsValue(o.Hours, t.Hour()) {
+		return false
+	}
+
+	return true
+}
+
+func containsValue[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ComputeWindRose bins observations by WindDirection and WindSpeedKnots.
+//
+// Observations with a missing wind speed are skipped. Calm observations have no
+// direction, while observations above calm with a missing direction (variable
+// winds) are skipped. North is reported as 360
+func ComputeWindRose(data []*IEMWeatherData, options WindRoseOptions) *WindRose {
+	defaults := DefaultWindRoseOptions()
+
+	if options.Sectors <= 0 {
+		options.Sectors = defaults.Sectors
+	}
+
+	if len(options.SpeedBins) == 0 {
+		options.SpeedBins = defaults.SpeedBins
+	}
+
+	// Speeds that are not calm but below the first bin get a bin of their own
+	if options.CalmKnots < options.SpeedBins[0] {
+		options.SpeedBins = append([]float64{options.CalmKnots}, options.SpeedBins...)
+	}
+
+	rose := &WindRose{
+		SpeedBins: windRoseSpeedBins(options.SpeedBins),
+		Sectors:   make([]*WindRoseSector, options.Sectors),
+	}
+
+	width := 360 / float64(options.Sectors)
+
+	for i := range rose.Sectors {
+		direction := float64(i) * width
+
+		rose.Sectors[i] = &WindRoseSector{
+			Label:     windRoseSectorLabel(options.Sectors, i, direction),
+			Direction: direction,
+			Counts:    make([]int, len(options.SpeedBins)),
+			Percents:  make([]float64, len(options.SpeedBins)),
+		}
+	}
+
+	for _, d := range data {
+		if !options.includes(d.Time) {
+			rose.Skipped++
+			continue
+		}
+
+		if d.IsMissing(WindSpeedKnots) {
+			rose.Skipped++
+			continue
+		}
+
+		if d.WindSpeedKnots < options.CalmKnots {
+			rose.Calm++
+			rose.Total++
+			continue
+		}
+
+		if d.IsMissing(WindDirection) {
+			rose.Skipped++
+			continue
+		}
+
+		sector := int(math.Mod(d.WindDirection+width/2, 360) / width)
+
+		bin := 0
+
+		for i, min := range options.SpeedBins {
+			if d.WindSpeedKnots >= min {
+				bin = i
+			}
+		}
+
+		rose.Sectors[sector].Counts[bin]++
+		rose.Total++
+	}
+
+	if rose.Total == 0 {
+		return rose
+	}
+
+	rose.CalmPercent = percent(rose.Calm, rose.Total)
+
+	for _, sector := range rose.Sectors {
+		for i, count := range sector.Counts {
+			sector.Percents[i] = percent(count, rose.Total)
+		}
+	}
+
+	return rose
+}
+
+func percent(count, total int) float64 {
+	return float64(count) / float64(total) * 100
+}
+
+// WriteCSV writes the wind rose percents with a row per sector and a column
+// per speed bin, followed by a calm row
+func (r *WindRose) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"label", "direction"}
+
+	for _, bin := range r.SpeedBins {
+		header = append(header, bin.Label)
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, sector := range r.Sectors {
+		row := []string{sector.Label, strconv.FormatFloat(sector.Direction, 'f', -1, 64)}
+
+		for _, p := range sector.Percents {
+			row = append(row, strconv.FormatFloat(p, 'f', 2, 64))
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	calm := make([]string, len(header))
+	calm[0] = "CALM"
+	calm[2] = strconv.FormatFloat(r.CalmPercent, 'f', 2, 64)
+
+	if err := writer.Write(calm); err != nil {
+		return err
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// HasWindRose reports whether IEM has computed wind roses for the network's stations
+func (n *Network) HasWindRose() bool {
+	return !n.WindroseUpdate.IsZero()
+}
+
+// WindRoseImageURL is the URL of IEM's period of record wind rose image for a station
+func (c *Client) WindRoseImageURL(network string, stationId string) string {
+	return fmt.Sprintf("%s/onsite/windrose/%s/%s/%s_yearly.png", c.baseUrl, network, stationId, stationId)
+}
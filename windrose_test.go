@@ -0,0 +1,97 @@
+package iem
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func windObservation(month time.Month, direction, speed float64) *IEMWeatherData {
+	t := time.Date(2023, month, 1, 12, 0, 0, 0, time.UTC)
+
+	return &IEMWeatherData{Time: &t, WindDirection: direction, WindSpeedKnots: speed}
+}
+
+func TestComputeWindRose(t *testing.T) {
+	data := []*IEMWeatherData{
+		windObservation(time.January, 360, 6),
+		windObservation(time.January, 10, 12),
+		windObservation(time.January, 180, 25),
+		windObservation(time.January, 0, 0),
+		windObservation(time.January, 0, 8),
+		windObservation(time.July, 180, 6),
+	}
+
+	// Variable winds have a speed but no direction
+	data[4].SetMissing(WindDirection)
+
+	options := DefaultWindRoseOptions()
+	options.Months = []time.Month{time.January}
+
+	rose := ComputeWindRose(data, options)
+
+	if rose.Total != 4 || rose.Calm != 1 || rose.Skipped != 2 {
+		t.Fatalf("unexpected totals %d %d %d", rose.Total, rose.Calm, rose.Skipped)
+	}
+
+	north := rose.Sectors[0]
+
+	if north.Label != "N" || north.Counts[1] != 1 || north.Counts[3] != 1 {
+		t.Errorf("unexpected north sector %+v", north)
+	}
+
+	south := rose.Sectors[8]
+
+	if south.Label != "S" || south.Counts[5] != 1 || south.Percents[5] != 25 {
+		t.Errorf("unexpected south sector %+v", south)
+	}
+
+	if rose.CalmPercent != 25 {
+		t.Errorf("expected 25%% calm, got %f", rose.CalmPercent)
+	}
+
+	var buf bytes.Buffer
+
+	if err := rose.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 18 || lines[0] != "label,direction,2-5,5-7,7-10,10-15,15-20,20+" {
+		t.Errorf("unexpected csv %q", lines[0])
+	}
+}
+
+func TestComputeWindRoseMissing(t *testing.T) {
+	body := "station,valid,drct,sknt\n" +
+		"LNK,2023-01-04 06:54,M,M\n" +
+		"LNK,2023-01-04 07:54,0.00,0.00\n" +
+		"LNK,2023-01-04 08:54,M,8.00\n" +
+		"LNK,2023-01-04 09:54,90.00,3.00\n"
+
+	data, err := ParseWeatherData(strings.NewReader(body), NewWeatherDataQuery())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefaultWindRoseOptions()
+	options.CalmKnots = 1
+	options.SpeedBins = []float64{5, 10}
+
+	rose := ComputeWindRose(data, options)
+
+	if rose.Total != 2 || rose.Calm != 1 || rose.Skipped != 2 || rose.CalmPercent != 50 {
+		t.Fatalf("unexpected totals %d %d %d %f", rose.Total, rose.Calm, rose.Skipped, rose.CalmPercent)
+	}
+
+	if len(rose.SpeedBins) != 3 || rose.SpeedBins[0].Label != "1-5" {
+		t.Fatalf("expected a bin between calm and the first bin, got %+v", rose.SpeedBins)
+	}
+
+	if east := rose.Sectors[4]; east.Counts[0] != 1 {
+		t.Errorf("expected 3 knots in the 1-5 bin, got %+v", east)
+	}
+}